@@ -2,8 +2,10 @@ package cli
 
 // Shortcuts is a map of all the shortcuts supported by the CLI
 var Shortcuts = map[string]string{
+	"apply":          "apps:apply",
 	"create":         "apps:create",
 	"destroy":        "apps:destroy",
+	"export":         "apps:export",
 	"info":           "apps:info",
 	"login":          "auth:login",
 	"logout":         "auth:logout",
@@ -19,9 +19,13 @@ func (d *DeisCmd) AnnotationList(appID string, format string) error {
 	}
 
 	config, err := config.List(settings.Client, appID)
-	if d.checkAPICompatibility(settings.Client, err) != nil {
+	if d.checkAPICompatibility(settings, err) != nil {
 		return err
 	}
+
+	if d.structured() {
+		return d.printStructured(api.Config{Annotations: config.Annotations})
+	}
 	var configOutput strings.Builder
 
 	appTypes := make([]string, 0, len(config.Annotations))
@@ -76,18 +80,23 @@ func (d *DeisCmd) AnnotationSet(appID string, appType string, annotationCommandL
 		return err
 	}
 
+	appAnnotations := make(map[string]api.Annotation)
+	appAnnotations[appType] = annotations
+	configObj := api.Config{Annotations: appAnnotations}
+
+	if d.DryRun {
+		return d.dryRunConfig(settings, appID, configObj, false)
+	}
+
 	d.Print("Creating Annotations... ")
 
 	quit := progress(d.WOut)
 
-	appAnnotations := make(map[string]api.Annotation)
-	appAnnotations[appType] = annotations
-	configObj := api.Config{Annotations: appAnnotations}
 	configObj, err = config.Set(settings.Client, appID, configObj)
 
 	quit <- true
 	<-quit
-	if d.checkAPICompatibility(settings.Client, err) != nil {
+	if d.checkAPICompatibility(settings, err) != nil {
 		return err
 	}
 
@@ -104,10 +113,6 @@ func (d *DeisCmd) AnnotationUnset(appID string, appType string, annotations []st
 		return err
 	}
 
-	d.Print("Removing Annotations... ")
-
-	quit := progress(d.WOut)
-
 	configObj := api.Config{}
 
 	valuesMap := make(map[string]interface{})
@@ -120,10 +125,18 @@ func (d *DeisCmd) AnnotationUnset(appID string, appType string, annotations []st
 	annotationMap[appType] = valuesMap
 	configObj.Annotations = annotationMap
 
+	if d.DryRun {
+		return d.dryRunConfig(s, appID, configObj, false)
+	}
+
+	d.Print("Removing Annotations... ")
+
+	quit := progress(d.WOut)
+
 	_, err = config.Set(s.Client, appID, configObj)
 	quit <- true
 	<-quit
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
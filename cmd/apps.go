@@ -1,18 +1,21 @@
 package cmd
 
 import (
-	"bufio"
 	"fmt"
-	"os"
+	"io"
+	"net/url"
+	"regexp"
 	"strings"
 	"time"
 
+	"github.com/deis/controller-sdk-go"
 	"github.com/deis/controller-sdk-go/api"
 	"github.com/deis/controller-sdk-go/apps"
+	"github.com/deis/controller-sdk-go/appsettings"
 	"github.com/deis/controller-sdk-go/config"
 	"github.com/deis/controller-sdk-go/domains"
+	"github.com/deis/controller-sdk-go/ps"
 	"github.com/deis/workflow-cli/pkg/git"
-	"github.com/deis/workflow-cli/pkg/logging"
 	"github.com/deis/workflow-cli/pkg/webbrowser"
 	"github.com/deis/workflow-cli/settings"
 )
@@ -31,7 +34,7 @@ func (d *DeisCmd) AppCreate(id, buildpack, remote string, noRemote bool) error {
 	quit <- true
 	<-quit
 
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
@@ -43,7 +46,7 @@ func (d *DeisCmd) AppCreate(id, buildpack, remote string, noRemote bool) error {
 				"BUILDPACK_URL": buildpack,
 			},
 		}
-		if _, err = config.Set(s.Client, app.ID, configValues); d.checkAPICompatibility(s.Client, err) != nil {
+		if _, err = config.Set(s.Client, app.ID, configValues); d.checkAPICompatibility(s, err) != nil {
 			return err
 		}
 	}
@@ -81,10 +84,14 @@ func (d *DeisCmd) AppsList(results int) error {
 	}
 
 	apps, count, err := apps.List(s.Client, results)
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
+	if d.structured() {
+		return d.printStructured(apps)
+	}
+
 	d.Printf("=== Apps%s", limitCount(len(apps), count))
 
 	for _, app := range apps {
@@ -102,10 +109,14 @@ func (d *DeisCmd) AppInfo(appID string) error {
 	}
 
 	app, err := apps.Get(s.Client, appID)
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
+	if d.structured() {
+		return d.appInfoStructured(s, app)
+	}
+
 	url, err := d.appURL(s, appID)
 	if err != nil {
 		return err
@@ -125,9 +136,11 @@ func (d *DeisCmd) AppInfo(appID string) error {
 
 	d.Println()
 	// print the app processes
-	if err = d.PsList(app.ID, defaultLimit); err != nil {
+	processes, _, err := ps.List(s.Client, app.ID, s.Limit)
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
+	printProcesses(app.ID, processes, d.WOut)
 
 	d.Println()
 	// print the app domains
@@ -146,6 +159,38 @@ func (d *DeisCmd) AppInfo(appID string) error {
 	return nil
 }
 
+// appInfo is the machine-readable representation of apps:info.
+type appInfo struct {
+	App       api.App                `json:"app"`
+	Processes []api.Pods             `json:"processes"`
+	Domains   []api.Domain           `json:"domains"`
+	Labels    map[string]interface{} `json:"labels"`
+}
+
+func (d *DeisCmd) appInfoStructured(s *settings.Settings, app api.App) error {
+	info := appInfo{App: app}
+
+	processes, _, err := ps.List(s.Client, app.ID, s.Limit)
+	if d.checkAPICompatibility(s, err) != nil {
+		return err
+	}
+	info.Processes = processes
+
+	domains, _, err := domains.List(s.Client, app.ID, s.Limit)
+	if d.checkAPICompatibility(s, err) != nil {
+		return err
+	}
+	info.Domains = domains
+
+	appSettings, err := appsettings.List(s.Client, app.ID)
+	if d.checkAPICompatibility(s, err) != nil {
+		return err
+	}
+	info.Labels = appSettings.Label
+
+	return d.printStructured(info)
+}
+
 // AppOpen opens an app in the default webbrowser.
 func (d *DeisCmd) AppOpen(appID string) error {
 	s, appID, err := load(d.ConfigFile, appID)
@@ -170,66 +215,104 @@ func (d *DeisCmd) AppOpen(appID string) error {
 	return webbrowser.Webbrowser(u)
 }
 
-// AppLogs returns the logs from an app.
-func (d *DeisCmd) AppLogs(appID string, lines int, process string, tail bool) error {
+// AppLogs returns the logs from an app. Lines can be filtered by process types, a regular
+// expression and a time range, and printed as JSON records.
+func (d *DeisCmd) AppLogs(appID string, lines int, processes []string, tail bool, grep string,
+	since, until time.Time, jsonOutput bool) error {
 	s, appID, err := load(d.ConfigFile, appID)
 
 	if err != nil {
 		return err
 	}
 
-	if !tail {
-		logs, err := apps.Logs(s.Client, appID, lines, process)
-		if d.checkAPICompatibility(s.Client, err) != nil {
+	filter := logFilter{processes: processes, since: since, until: until}
+	if grep != "" {
+		if filter.grep, err = regexp.Compile(grep); err != nil {
 			return err
 		}
+	}
 
-		for _, log := range strings.Split(strings.TrimRight(logs, `\n`), `\n`) {
-			logging.PrintLog(os.Stdout, log)
-		}
-	} else {
-		res, err := apps.LogsTail(s.Client, appID, process)
+	// The controller can only filter a single process type.
+	process := ""
+	if len(processes) == 1 {
+		process = processes[0]
+	}
 
-		if err != nil {
-			return err
-		}
+	jsonOutput = jsonOutput || d.structured()
+
+	if tail {
+		return d.tailLogs(s, appID, process, filter, jsonOutput)
+	}
 
-		reader := bufio.NewReader(res.Body)
-		for {
-			line, err := reader.ReadBytes('\n')
-			if err != nil {
+	logs, err := apps.Logs(s.Client, appID, lines, process)
+	if d.checkAPICompatibility(s, err) != nil {
+		return err
+	}
+
+	for _, line := range splitLogs(logs) {
+		if line == "" {
+			continue
+		}
+		if record := parseLogLine(appID, line); filter.matches(record) {
+			if err = d.printLogRecord(record, jsonOutput); err != nil {
 				return err
 			}
-			logging.PrintLog(os.Stdout, strings.TrimRight(string(line), "\n"))
 		}
 	}
 
 	return nil
 }
 
-// AppRun runs a one time command in the app.
-func (d *DeisCmd) AppRun(appID, command string) error {
+// AppRun runs a one time command in the app, streaming its output as it runs. If tty is true,
+// the command gets a terminal and its input is streamed too. If timeout isn't zero, the command
+// is cancelled once it passes. A non-zero exit code is returned as an ExitError.
+func (d *DeisCmd) AppRun(appID, command string, tty bool, timeout time.Duration) error {
 	s, appID, err := load(d.ConfigFile, appID)
 
 	if err != nil {
 		return err
 	}
 
-	d.Printf("Running '%s'...\n", command)
+	var in io.Reader
+	if tty {
+		in = d.WIn
+	} else {
+		d.Printf("Running '%s'...\n", command)
+	}
+
+	query := url.Values{"command": {command}}
+	err = d.streamCommand(s, fmt.Sprintf("/v2/apps/%s/run", appID), query, in, tty, timeout)
+	if (err == deis.ErrNotFound || err == deis.ErrNotAllowed) && !tty {
+		// Controllers without the streaming endpoint only run commands to completion.
+		return d.runToCompletion(s, appID, command, timeout)
+	}
+
+	return err
+}
+
+// runToCompletion runs a one time command in the app and prints its output once it exits.
+func (d *DeisCmd) runToCompletion(s *settings.Settings, appID, command string, timeout time.Duration) error {
+	var out api.AppRunResponse
+	done := make(chan error, 1)
+
+	go func() {
+		var err error
+		out, err = apps.Run(s.Client, appID, command)
+		done <- d.checkAPICompatibility(s, err)
+	}()
 
-	out, err := apps.Run(s.Client, appID, command)
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	// The command keeps running when the request is abandoned.
+	if err := d.waitCancellable(done, timeout, func() bool { return false }); err != nil {
 		return err
 	}
 
 	if out.ReturnCode == 0 {
 		d.Print(out.Output)
-	} else {
-		d.PrintErr(out.Output)
+		return nil
 	}
 
-	os.Exit(out.ReturnCode)
-	return nil
+	d.PrintErr(out.Output)
+	return ExitError{Code: out.ReturnCode}
 }
 
 // AppDestroy destroys an app.
@@ -269,7 +352,7 @@ func (d *DeisCmd) AppDestroy(appID, confirm string) error {
 	startTime := time.Now()
 	d.Printf("Destroying %s...\n", appID)
 
-	if err = apps.Delete(s.Client, appID); d.checkAPICompatibility(s.Client, err) != nil {
+	if err = apps.Delete(s.Client, appID); d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
@@ -293,7 +376,7 @@ func (d *DeisCmd) AppTransfer(appID, username string) error {
 	d.Printf("Transferring %s to %s... ", appID, username)
 
 	err = apps.Transfer(s.Client, appID, username)
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
@@ -307,7 +390,7 @@ const noDomainAssignedMsg = "No domain assigned to %s"
 // appURL grabs the first domain an app has and returns this.
 func (d *DeisCmd) appURL(s *settings.Settings, appID string) (string, error) {
 	domains, _, err := domains.List(s.Client, appID, 1)
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return "", err
 	}
 
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/arschles/assert"
 
+	"github.com/deis/controller-sdk-go/api"
 	"github.com/deis/workflow-cli/pkg/git"
 	"github.com/deis/workflow-cli/pkg/testutil"
 	"github.com/deis/workflow-cli/settings"
@@ -69,6 +71,47 @@ consectetur
 `, "output")
 }
 
+func TestAppsListStructured(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	var b bytes.Buffer
+	cmdr := DeisCmd{WOut: &b, ConfigFile: cf, Output: OutputJSON}
+
+	server.Mux.HandleFunc("/v2/apps/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{
+			"count": 1,
+			"next": null,
+			"previous": null,
+			"results": [
+				{
+					"uuid": "c4aed81c-d1ca-4ff1-ab89-d2151264e1a3",
+					"id": "lorem-ipsum",
+					"owner": "dolar-sit-amet",
+					"created": "2016-08-22T17:40:16Z",
+					"updated": "2016-08-22T17:40:16Z",
+					"structure": {
+						"cmd": 1
+					}
+				}
+			]
+		}`)
+	})
+
+	err = cmdr.AppsList(-1)
+	assert.NoErr(t, err)
+
+	var apps []api.App
+	assert.NoErr(t, json.Unmarshal(b.Bytes(), &apps))
+	assert.Equal(t, len(apps), 1, "apps")
+	assert.Equal(t, apps[0].ID, "lorem-ipsum", "app id")
+	assert.Equal(t, apps[0].Owner, "dolar-sit-amet", "app owner")
+}
+
 func TestAppsListLimit(t *testing.T) {
 	t.Parallel()
 	cf, server, err := testutil.NewTestServerAndClient()
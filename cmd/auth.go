@@ -3,8 +3,11 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"syscall"
+	"text/tabwriter"
 
 	deis "github.com/deis/controller-sdk-go"
 	"github.com/deis/controller-sdk-go/auth"
@@ -15,6 +18,10 @@ import (
 // Register creates a account on a Deis controller.
 func (d *DeisCmd) Register(controller string, username string, password string, email string,
 	sslVerify, login bool) error {
+	// The client is created here, without the transport that refuses changes on --dry-run.
+	if d.DryRun {
+		return settings.ErrReadOnly
+	}
 
 	c, err := deis.New(sslVerify, controller, "")
 
@@ -30,8 +37,9 @@ func (d *DeisCmd) Register(controller string, username string, password string,
 
 	// Set user agent for temporary client.
 	c.UserAgent = settings.UserAgent
+	s := &settings.Settings{Client: c}
 
-	if err = c.CheckConnection(); d.checkAPICompatibility(c, err) != nil {
+	if err = c.CheckConnection(); d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
@@ -69,7 +77,7 @@ func (d *DeisCmd) Register(controller string, username string, password string,
 
 	c.Token = ""
 
-	if d.checkAPICompatibility(c, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		d.PrintErr("Registration failed: ")
 		return err
 	}
@@ -77,7 +85,7 @@ func (d *DeisCmd) Register(controller string, username string, password string,
 	d.Printf("Registered %s\n", username)
 
 	if login {
-		return d.Login(controller, username, password, sslVerify, false)
+		return d.Login(controller, username, password, sslVerify, false, "", "", "", "")
 	}
 
 	return nil
@@ -88,14 +96,15 @@ func (d *DeisCmd) doGoogleAuthLogin(s settings.Settings) error {
 	if err != nil {
 		return err
 	}
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(&s, err) != nil {
 		return err
 	}
 	s.Client.Token = code
 	s.Username = strings.Split(email, "@")[0]
+	s.LoginMethod = settings.GoogleLogin
 	filename, err := s.Save(d.ConfigFile)
 	if err != nil {
-		return nil
+		return err
 	}
 
 	d.Printf("Logged in!\n")
@@ -105,17 +114,18 @@ func (d *DeisCmd) doGoogleAuthLogin(s settings.Settings) error {
 
 func (d *DeisCmd) doLogin(s settings.Settings, username, password string) error {
 	token, err := auth.Login(s.Client, username, password)
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(&s, err) != nil {
 		return err
 	}
 
 	s.Client.Token = token
 	s.Username = username
+	s.LoginMethod = settings.PasswordLogin
 
 	filename, err := s.Save(d.ConfigFile)
 
 	if err != nil {
-		return nil
+		return err
 	}
 
 	d.Printf("Logged in as %s\n", username)
@@ -123,23 +133,25 @@ func (d *DeisCmd) doLogin(s settings.Settings, username, password string) error
 	return nil
 }
 
-// Login to a Deis controller.
-func (d *DeisCmd) Login(controller string, username string, password string, sslVerify, googleAuth bool) error {
-	c, err := deis.New(sslVerify, controller, "")
+// Login to a Deis controller. The token is kept in credentialStore, or in the store used
+// before if it's empty. caFile, clientCert and clientKey are PEM files to verify the
+// controller with and authenticate to it with mutual TLS.
+func (d *DeisCmd) Login(controller string, username string, password string, sslVerify, googleAuth bool,
+	credentialStore, caFile, clientCert, clientKey string) error {
+	if d.DryRun {
+		return settings.ErrReadOnly
+	}
+
+	s, err := d.loginSettings(controller, sslVerify, credentialStore, caFile, clientCert, clientKey)
 
 	if err != nil {
 		return err
 	}
 
-	// Set user agent for temporary client.
-	c.UserAgent = settings.UserAgent
-
-	if err = c.CheckConnection(); d.checkAPICompatibility(c, err) != nil {
+	if err = s.Client.CheckConnection(); d.checkAPICompatibility(&s, err) != nil {
 		return err
 	}
 
-	s := settings.Settings{Client: c}
-
 	if googleAuth == true {
 		return d.doGoogleAuthLogin(s)
 	}
@@ -162,8 +174,60 @@ func (d *DeisCmd) Login(controller string, username string, password string, ssl
 	return d.doLogin(s, username, password)
 }
 
+// loginSettings returns the settings to log in to a controller with, and to save once logged
+// in. The token is kept in credentialStore, or in the store used before if it's empty. The
+// certificate files are kept from the last login to the same controller if none are given.
+func (d *DeisCmd) loginSettings(controller string, sslVerify bool, credentialStore, caFile, clientCert,
+	clientKey string) (settings.Settings, error) {
+	c, err := deis.New(sslVerify, controller, "")
+
+	if err != nil {
+		return settings.Settings{}, err
+	}
+
+	// Set user agent for temporary client.
+	c.UserAgent = settings.UserAgent
+
+	s := settings.Settings{Client: c}
+
+	// Keep the secret patterns the user added and the credential store when logging in again.
+	// Falling back to the defaults would write the token to another store than the user chose.
+	previous, err := settings.Saved(d.ConfigFile)
+	if err == nil {
+		s.SecretPatterns = previous.SecretPatterns
+		s.CredentialStore = previous.CredentialStore
+
+		if caFile == "" && clientCert == "" && clientKey == "" &&
+			previous.Client.ControllerURL.String() == c.ControllerURL.String() {
+			caFile, clientCert, clientKey = previous.CAFile, previous.ClientCert, previous.ClientKey
+		}
+	} else if !os.IsNotExist(err) {
+		return settings.Settings{}, err
+	}
+
+	if credentialStore != "" {
+		s.CredentialStore = credentialStore
+	}
+
+	// The files are read again by every command, which may run from another directory.
+	for _, file := range []*string{&caFile, &clientCert, &clientKey} {
+		if *file != "" {
+			if *file, err = filepath.Abs(*file); err != nil {
+				return settings.Settings{}, err
+			}
+		}
+	}
+	s.CAFile, s.ClientCert, s.ClientKey = caFile, clientCert, clientKey
+
+	return s, settings.ConfigureTLS(c, caFile, clientCert, clientKey)
+}
+
 // Logout from a Deis controller.
 func (d *DeisCmd) Logout() error {
+	if d.DryRun {
+		return settings.ErrReadOnly
+	}
+
 	if err := settings.Delete(d.ConfigFile); err != nil {
 		return err
 	}
@@ -212,7 +276,7 @@ func (d *DeisCmd) Passwd(username, password, newPassword string) error {
 	}
 
 	err = auth.Passwd(s.Client, username, password, newPassword)
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		d.PrintErr("Password change failed: ")
 		return err
 	}
@@ -232,14 +296,14 @@ func (d *DeisCmd) Cancel(username, password string, yes bool, googleAuth bool) e
 	if (username == "" || password != "") && googleAuth == false {
 		d.Println("Please log in again in order to cancel this account")
 
-		if err = d.Login(s.Client.ControllerURL.String(), username, password, s.Client.VerifySSL, false); err != nil {
+		if err = d.Login(s.Client.ControllerURL.String(), username, password, s.Client.VerifySSL, false, "", "", "", ""); err != nil {
 			return err
 		}
 	}
 
 	if googleAuth == true {
 		d.Println("Please log in again in order to cancel this account")
-		if err = d.Login(s.Client.ControllerURL.String(), username, password, s.Client.VerifySSL, true); err != nil {
+		if err = d.Login(s.Client.ControllerURL.String(), username, password, s.Client.VerifySSL, true, "", "", "", ""); err != nil {
 			return err
 		}
 	}
@@ -275,7 +339,7 @@ func (d *DeisCmd) Cancel(username, password string, yes bool, googleAuth bool) e
 	err = auth.Delete(s.Client, username)
 	if err == deis.ErrConflict {
 		return fmt.Errorf("%s still has applications associated with it. Transfer ownership or delete them first", username)
-	} else if d.checkAPICompatibility(s.Client, err) != nil {
+	} else if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
@@ -299,14 +363,28 @@ func (d *DeisCmd) Whoami(all bool) error {
 		return err
 	}
 
-	if all {
+	if all || d.structured() {
 		user, err := auth.Whoami(s.Client)
 		if err != nil {
 			return err
 		}
+		if d.structured() {
+			return d.printStructured(user)
+		}
 		d.Println(user)
 	} else {
 		d.Printf("You are %s at %s\n", s.Username, s.Client.ControllerURL.String())
+
+		// Tell where each setting came from, as the environment overrides the settings file.
+		w := tabwriter.NewWriter(d.WOut, 0, 8, 1, ' ', 0)
+		for _, name := range settings.SettingNames {
+			if source := s.Sources[name]; source == settings.DefaultSource {
+				fmt.Fprintf(w, "%s\t%s\n", name, source)
+			} else {
+				fmt.Fprintf(w, "%s\tfrom %s\n", name, source)
+			}
+		}
+		w.Flush()
 	}
 	return nil
 }
@@ -320,21 +398,31 @@ func (d *DeisCmd) Regenerate(username string, all bool) error {
 	}
 
 	token, err := auth.Regenerate(s.Client, username, all)
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
-	if username == "" && !all {
-		s.Client.Token = token
-		_, err = s.Save(d.ConfigFile)
+	d.Println("Token Regenerated")
+
+	if username != "" || all {
+		return nil
+	}
+
+	// The old token stays in the environment, so the new one is only shown.
+	if s.Sources["token"] == "$"+settings.TokenEnv {
+		d.PrintErrf("$%s still holds the old token, set it to the new one: %s\n", settings.TokenEnv, token)
+		return nil
+	}
 
-		if err != nil {
-			return err
-		}
+	// The loaded settings include the environment overrides, which aren't saved.
+	saved, err := settings.Saved(d.ConfigFile)
+	if err != nil {
+		return err
 	}
 
-	d.Println("Token Regenerated")
-	return nil
+	saved.Client.Token = token
+	_, err = saved.Save(d.ConfigFile)
+	return err
 }
 
 func readPassword() (string, error) {
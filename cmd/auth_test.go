@@ -2,12 +2,20 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/pem"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"log"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/arschles/assert"
 	"github.com/deis/workflow-cli/pkg/testutil"
+	"github.com/deis/workflow-cli/settings"
 )
 
 func TestRegister(t *testing.T) {
@@ -79,10 +87,71 @@ func TestLogin(t *testing.T) {
 	})
 
 	username := "test-user"
-	err = cmdr.Login(server.Server.URL, username, "test-pass", true, false)
+	err = cmdr.Login(server.Server.URL, username, "test-pass", true, false, "", "", "", "")
 	assert.NoErr(t, err)
 	expected := fmt.Sprintf("Logged in as %s\nConfiguration file written to %s\n", username, cf)
 	assert.Equal(t, b.String(), expected, "output")
+
+	// A token that can't be stored is a failed login.
+	b.Reset()
+	err = cmdr.Login(server.Server.URL, username, "test-pass", true, false, "missing", "", "", "")
+	assert.Err(t, errors.New("credential helper deis-credential-missing not found on the PATH"), err)
+	assert.Equal(t, b.String(), "", "output")
+}
+
+func TestLoginCAFile(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	server := httptest.NewUnstartedServer(mux)
+	// Don't log the handshake that fails on purpose.
+	server.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprintf(w, `{}`)
+	})
+
+	mux.HandleFunc("/v2/auth/login/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"token":"abc"}`)
+	})
+
+	dir, err := ioutil.TempDir("", "client")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cf := filepath.Join(dir, "test.json")
+	caFile := filepath.Join(dir, "ca.pem")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err = ioutil.WriteFile(caFile, ca, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var b bytes.Buffer
+	cmdr := DeisCmd{WOut: &b, ConfigFile: cf}
+
+	// The controller's certificate is verified against the CA.
+	err = cmdr.Login(server.URL, "test-user", "test-pass", true, false, "", "", "", "")
+	assert.ExistsErr(t, err, "unverified certificate")
+
+	err = cmdr.Login(server.URL, "test-user", "test-pass", true, false, "", caFile, "", "")
+	assert.NoErr(t, err)
+	assert.Equal(t, b.String(), fmt.Sprintf("Logged in as test-user\nConfiguration file written to %s\n", cf), "output")
+
+	s, err := settings.Load(cf)
+	assert.NoErr(t, err)
+	assert.Equal(t, s.CAFile, caFile, "CA file")
+	assert.Equal(t, s.Client.Token, "abc", "token")
+
+	// Logging in again to the same controller keeps the CA.
+	err = cmdr.Login(server.URL, "test-user", "test-pass", true, false, "", "", "", "")
+	assert.NoErr(t, err)
 }
 
 func TestLogout(t *testing.T) {
@@ -99,6 +168,38 @@ func TestLogout(t *testing.T) {
 	assert.Equal(t, b.String(), "Logged out\n", "output")
 }
 
+func TestAuthDryRun(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	server.Mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected %s request to %s", r.Method, r.URL.Path)
+	})
+
+	var b bytes.Buffer
+	cmdr := DeisCmd{WOut: &b, ConfigFile: cf, DryRun: true}
+
+	err = cmdr.Register(server.Server.URL, "test", "opensesame", "test@example.com", true, true)
+	assert.Err(t, settings.ErrReadOnly, err)
+
+	err = cmdr.Login(server.Server.URL, "test", "opensesame", true, false, "", "", "", "")
+	assert.Err(t, settings.ErrReadOnly, err)
+
+	err = cmdr.LoginSSO(server.Server.URL, "", "", true, "", "", "", "")
+	assert.Err(t, settings.ErrReadOnly, err)
+
+	err = cmdr.Logout()
+	assert.Err(t, settings.ErrReadOnly, err)
+
+	_, err = os.Stat(cf)
+	assert.NoErr(t, err)
+	assert.Equal(t, b.String(), "", "output")
+}
+
 func TestPasswd(t *testing.T) {
 	t.Parallel()
 
@@ -188,7 +289,13 @@ func TestWhoami(t *testing.T) {
 
 	err = cmdr.Whoami(false)
 	assert.NoErr(t, err)
-	expected := fmt.Sprintf("You are test at %s\n", server.Server.URL)
+	expected := fmt.Sprintf(`You are test at %s
+controller     from %s
+username       from %s
+token          default
+ssl_verify     from %s
+response_limit default
+`, server.Server.URL, cf, cf, cf)
 	assert.Equal(t, b.String(), expected, "output")
 }
 
@@ -256,3 +363,37 @@ func TestRegenerate(t *testing.T) {
 	assert.NoErr(t, err)
 	assert.Equal(t, b.String(), "Token Regenerated\n", "output")
 }
+
+func TestRegenerateEnvironment(t *testing.T) {
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	server.Mux.HandleFunc("/v2/auth/tokens/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"token":"new"}`)
+	})
+
+	var b, e bytes.Buffer
+	cmdr := DeisCmd{WOut: &b, WErr: &e, ConfigFile: cf}
+
+	// The environment overrides aren't written to the settings file with the new token.
+	os.Setenv(settings.UsernameEnv, "other")
+	err = cmdr.Regenerate("", false)
+	os.Unsetenv(settings.UsernameEnv)
+	assert.NoErr(t, err)
+
+	s, err := settings.Load(cf)
+	assert.NoErr(t, err)
+	assert.Equal(t, s.Username, "test", "username")
+	assert.Equal(t, s.Client.Token, "new", "token")
+
+	// A token given in the environment isn't saved, as it would still be used instead.
+	os.Setenv(settings.TokenEnv, "new")
+	err = cmdr.Regenerate("", false)
+	os.Unsetenv(settings.TokenEnv)
+	assert.NoErr(t, err)
+	assert.Equal(t, e.String(), "$DEIS_TOKEN still holds the old token, set it to the new one: new\n", "errors")
+}
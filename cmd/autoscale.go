@@ -14,10 +14,14 @@ func (d *DeisCmd) AutoscaleList(appID string) error {
 	}
 
 	appSettings, err := appsettings.List(s.Client, appID)
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
+	if d.structured() {
+		return d.printStructured(api.AppSettings{Autoscale: appSettings.Autoscale})
+	}
+
 	d.Printf("=== %s Autoscale\n\n", appID)
 
 	if appSettings.Autoscale == nil {
@@ -40,9 +44,6 @@ func (d *DeisCmd) AutoscaleSet(appID string, processType string, min int, max in
 		return err
 	}
 
-	d.Printf("Applying autoscale settings for process type %s on %s... ", processType, appID)
-
-	quit := progress(d.WOut)
 	data := map[string]*api.Autoscale{
 		processType: {
 			Min:        min,
@@ -50,6 +51,15 @@ func (d *DeisCmd) AutoscaleSet(appID string, processType string, min int, max in
 			CPUPercent: CPUPercent,
 		},
 	}
+
+	if d.DryRun {
+		return d.dryRunAppSettings(s, appID, api.AppSettings{Autoscale: data})
+	}
+
+	d.Printf("Applying autoscale settings for process type %s on %s... ", processType, appID)
+
+	quit := progress(d.WOut)
+
 	_, err = appsettings.Set(s.Client, appID, api.AppSettings{Autoscale: data})
 
 	quit <- true
@@ -71,12 +81,18 @@ func (d *DeisCmd) AutoscaleUnset(appID string, processType string) error {
 		return err
 	}
 
-	d.Printf("Removing autoscale for process type %s on %s... ", processType, appID)
-
-	quit := progress(d.WOut)
 	data := map[string]*api.Autoscale{
 		processType: nil,
 	}
+
+	if d.DryRun {
+		return d.dryRunAppSettings(s, appID, api.AppSettings{Autoscale: data})
+	}
+
+	d.Printf("Removing autoscale for process type %s on %s... ", processType, appID)
+
+	quit := progress(d.WOut)
+
 	_, err = appsettings.Set(s.Client, appID, api.AppSettings{Autoscale: data})
 
 	quit <- true
@@ -1,14 +1,26 @@
 package cmd
 
 import (
-	"io/ioutil"
-	"os"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/deis/controller-sdk-go/api"
 	"github.com/deis/controller-sdk-go/builds"
+	"github.com/deis/workflow-cli/settings"
 	"github.com/ghodss/yaml"
+	"github.com/olekukonko/tablewriter"
 )
 
-// BuildsList lists an app's builds.
+// buildDetails is a build along with the releases that used it.
+type buildDetails struct {
+	api.Build
+	Releases []string `json:"releases"`
+}
+
+// BuildsList lists an app's builds, with their image, owner, SHA, process types, sidecars,
+// whether they were built from a Dockerfile and the releases that used them.
 func (d *DeisCmd) BuildsList(appID string, results int) error {
 	s, appID, err := load(d.ConfigFile, appID)
 
@@ -21,71 +33,261 @@ func (d *DeisCmd) BuildsList(appID string, results int) error {
 	}
 
 	builds, count, err := builds.List(s.Client, appID, results)
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
-	d.Printf("=== %s Builds%s", appID, limitCount(len(builds), count))
+	used, err := d.buildReleases(s, appID)
+	if err != nil {
+		return err
+	}
 
+	details := make([]buildDetails, 0, len(builds))
 	for _, build := range builds {
-		d.Println(build.UUID, build.Created)
+		details = append(details, buildDetails{Build: build, Releases: used[build.UUID]})
+	}
+
+	if d.structured() {
+		return d.printStructured(details)
+	}
+
+	d.Printf("=== %s Builds%s", appID, limitCount(len(builds), count))
+
+	if len(details) == 0 {
+		return nil
+	}
+
+	table := tablewriter.NewWriter(d.WOut)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetBorder(false)
+	table.SetAutoFormatHeaders(false)
+	table.SetAutoWrapText(false)
+	table.SetHeaderLine(true)
+	table.SetHeader([]string{"UUID", "Created", "Owner", "Image", "SHA", "Types", "Sidecars",
+		"Dockerfile", "Releases"})
+
+	for _, build := range details {
+		table.Append([]string{build.UUID, build.Created, build.Owner, build.Image, shortSHA(build.Sha),
+			strings.Join(sortedTypes(build.Procfile), ","), strings.Join(sidecarTypes(build.Sidecarfile), ","),
+			yesNo(build.Dockerfile != ""), strings.Join(build.Releases, ",")})
 	}
+
+	table.Render()
 	return nil
 }
 
-// BuildsCreate creates a build for an app.
-func (d *DeisCmd) BuildsCreate(appID, image, procfile, sidecarfile string) error {
+// BuildsInfo prints the details of a build: its image, owner, SHA, Procfile, Sidecarfile, and
+// the releases that used it.
+func (d *DeisCmd) BuildsInfo(appID, uuid string) error {
 	s, appID, err := load(d.ConfigFile, appID)
 
 	if err != nil {
 		return err
 	}
 
-	procfileMap := make(map[string]string)
+	appBuilds, err := d.appBuilds(s, appID)
+	if err != nil {
+		return err
+	}
 
-	if procfile != "" {
-		if procfileMap, err = parseProcfile([]byte(procfile)); err != nil {
-			return err
-		}
-	} else if _, err := os.Stat("Procfile"); err == nil {
-		contents, err := ioutil.ReadFile("Procfile")
-		if err != nil {
-			return err
-		}
+	build, ok := appBuilds[uuid]
+	if !ok {
+		return fmt.Errorf("Could not find build %s in app %s", uuid, appID)
+	}
 
-		if procfileMap, err = parseProcfile(contents); err != nil {
-			return err
-		}
+	used, err := d.buildReleases(s, appID)
+	if err != nil {
+		return err
 	}
 
-	sidecarfileMap := make(map[string]interface{})
+	details := buildDetails{Build: build, Releases: used[uuid]}
 
-	if sidecarfile != "" {
-		if sidecarfileMap, err = parseSidecarfile([]byte(sidecarfile)); err != nil {
-			return err
-		}
-	} else if _, err := os.Stat("Sidecarfile"); err == nil {
-		contents, err := ioutil.ReadFile("Sidecarfile")
-		if err != nil {
-			return err
+	if d.structured() {
+		return d.printStructured(details)
+	}
+
+	sha := build.Sha
+	if sha == "" {
+		sha = "none"
+	}
+
+	releasesUsed := "none"
+	if len(details.Releases) > 0 {
+		releasesUsed = strings.Join(details.Releases, ", ")
+	}
+
+	d.Printf("=== %s Build %s\n", appID, build.UUID)
+	d.Println("created:   ", build.Created)
+	d.Println("owner:     ", build.Owner)
+	d.Println("image:     ", build.Image)
+	d.Println("sha:       ", sha)
+	d.Println("dockerfile:", yesNo(build.Dockerfile != ""))
+	d.Println("releases:  ", releasesUsed)
+
+	d.Println()
+	d.Println("--- Procfile")
+	if len(build.Procfile) == 0 {
+		d.Println("No process types defined.")
+	}
+	for _, procType := range sortedTypes(build.Procfile) {
+		d.Printf("%s: %s\n", procType, build.Procfile[procType])
+	}
+
+	d.Println()
+	d.Println("--- Sidecarfile")
+	if len(build.Sidecarfile) == 0 {
+		d.Println("No sidecars defined.")
+		return nil
+	}
+
+	sidecars, err := yaml.Marshal(build.Sidecarfile)
+	if err != nil {
+		return err
+	}
+	d.Print(string(sidecars))
+
+	return nil
+}
+
+// buildReleases returns the releases of an app that used each build, by build UUID, oldest
+// first.
+func (d *DeisCmd) buildReleases(s *settings.Settings, appID string) (map[string][]string, error) {
+	appReleases, err := d.allReleases(s, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	used := make(map[string][]string)
+	for i := len(appReleases) - 1; i >= 0; i-- {
+		if release := appReleases[i]; release.Build != "" {
+			used[release.Build] = append(used[release.Build], fmt.Sprintf("v%d", release.Version))
 		}
+	}
+
+	return used, nil
+}
+
+// appBuilds returns every build of an app by UUID.
+func (d *DeisCmd) appBuilds(s *settings.Settings, appID string) (map[string]api.Build, error) {
+	appBuilds, count, err := builds.List(s.Client, appID, s.Limit)
+	if d.checkAPICompatibility(s, err) != nil {
+		return nil, err
+	}
 
-		if sidecarfileMap, err = parseSidecarfile(contents); err != nil {
-			return err
+	if count > len(appBuilds) {
+		appBuilds, _, err = builds.List(s.Client, appID, count)
+		if d.checkAPICompatibility(s, err) != nil {
+			return nil, err
 		}
 	}
 
+	byUUID := make(map[string]api.Build, len(appBuilds))
+	for _, build := range appBuilds {
+		byUUID[build.UUID] = build
+	}
+
+	return byUUID, nil
+}
+
+// sortedTypes returns the process types of a Procfile, sorted.
+func sortedTypes(procfile map[string]string) []string {
+	types := make([]string, 0, len(procfile))
+	for procType := range procfile {
+		types = append(types, procType)
+	}
+	sort.Strings(types)
+
+	return types
+}
+
+// sidecarTypes returns the process types of a Sidecarfile that have sidecars, sorted.
+func sidecarTypes(sidecarfile map[string]interface{}) []string {
+	types := make([]string, 0, len(sidecarfile))
+	for procType := range sidecarfile {
+		types = append(types, procType)
+	}
+	sort.Strings(types)
+
+	return types
+}
+
+// shortSHA abbreviates a git commit SHA the way git does.
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+
+	return sha
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+
+	return "no"
+}
+
+// BuildsCreate creates a build for an app, after checking its Procfile and Sidecarfile. If
+// wait isn't zero, it waits up to that long for the processes to be up on the new release.
+func (d *DeisCmd) BuildsCreate(appID, image, procfile, sidecarfile string, wait time.Duration) error {
+	s, appID, err := load(d.ConfigFile, appID)
+
+	if err != nil {
+		return err
+	}
+
+	procfileMap, sidecarfileMap, err := loadBuildFiles(procfile, sidecarfile)
+	if err != nil {
+		return err
+	}
+
+	if err = validateBuildFiles(procfileMap, sidecarfileMap); err != nil {
+		return err
+	}
+
 	d.Print("Creating build... ")
 	quit := progress(d.WOut)
 	_, err = builds.New(s.Client, appID, image, procfileMap, sidecarfileMap)
 	quit <- true
 	<-quit
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
 	d.Println("done")
 
+	if wait > 0 {
+		return d.waitForLatestRelease(s, appID, nil, wait)
+	}
+
+	return nil
+}
+
+// BuildsValidate checks a Procfile and a Sidecarfile, given as YAML strings or read from the
+// current directory, without creating a build.
+func (d *DeisCmd) BuildsValidate(procfile, sidecarfile string) error {
+	procfileMap, sidecarfileMap, err := loadBuildFiles(procfile, sidecarfile)
+	if err != nil {
+		return err
+	}
+
+	if err = validateBuildFiles(procfileMap, sidecarfileMap); err != nil {
+		return err
+	}
+
+	if len(procfileMap) == 0 {
+		d.Println("No Procfile found.")
+	} else {
+		d.Printf("Procfile is valid: %s\n", strings.Join(sortedTypes(procfileMap), ", "))
+	}
+
+	if len(sidecarfileMap) == 0 {
+		d.Println("No Sidecarfile found.")
+	} else {
+		d.Printf("Sidecarfile is valid: %s\n", strings.Join(sidecarTypes(sidecarfileMap), ", "))
+	}
+
 	return nil
 }
 
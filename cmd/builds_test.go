@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -59,6 +60,11 @@ func TestBuildsList(t *testing.T) {
 	var b bytes.Buffer
 	cmdr := DeisCmd{WOut: &b, ConfigFile: cf}
 
+	handleReleases(t, server, "foo",
+		api.Release{Version: 3, Build: "c4aed81c-d1ca-4ff1-ab89-d2151264e1a3"},
+		api.Release{Version: 2, Build: "de1bf5b5-4a72-4f94-a10c-d2a3741cdf75"},
+		api.Release{Version: 1})
+
 	server.Mux.HandleFunc("/v2/apps/foo/builds/", func(w http.ResponseWriter, r *http.Request) {
 		testutil.SetHeaders(w)
 		fmt.Fprintf(w, `{
@@ -70,9 +76,9 @@ func TestBuildsList(t *testing.T) {
 					"app": "",
 					"created": "2014-01-01T00:00:00UTC",
 					"dockerfile": "",
-					"image": "",
-					"owner": "",
-					"procfile": {},
+					"image": "foo/web:v1",
+					"owner": "admin",
+					"procfile": {"web": "./server", "worker": "./work"},
 					"sidecarfile": {},
 					"sha": "",
 					"updated": "",
@@ -81,12 +87,12 @@ func TestBuildsList(t *testing.T) {
 				{
 					"app": "",
 					"created": "2014-01-05T00:00:00UTC",
-					"dockerfile": "",
+					"dockerfile": "FROM busybox",
 					"image": "",
-					"owner": "",
+					"owner": "jane",
 					"procfile": {},
-					"sidecarfile": {},
-					"sha": "",
+					"sidecarfile": {"web": [{"name": "proxy", "image": "envoy:latest"}]},
+					"sha": "8f1b5c6a2e9d4f7b3c0a1e2d3f4a5b6c7d8e9f0a",
 					"updated": "",
 					"uuid": "c4aed81c-d1ca-4ff1-ab89-d2151264e1a3"
 				}
@@ -97,8 +103,10 @@ func TestBuildsList(t *testing.T) {
 	err = cmdr.BuildsList("foo", -1)
 	assert.NoErr(t, err)
 	assert.Equal(t, b.String(), `=== foo Builds
-de1bf5b5-4a72-4f94-a10c-d2a3741cdf75 2014-01-01T00:00:00UTC
-c4aed81c-d1ca-4ff1-ab89-d2151264e1a3 2014-01-05T00:00:00UTC
+                  UUID                 |        Created         | Owner |   Image    |   SHA   |   Types    | Sidecars | Dockerfile | Releases  
++--------------------------------------+------------------------+-------+------------+---------+------------+----------+------------+----------+
+  de1bf5b5-4a72-4f94-a10c-d2a3741cdf75 | 2014-01-01T00:00:00UTC | admin | foo/web:v1 |         | web,worker |          | no         | v2        
+  c4aed81c-d1ca-4ff1-ab89-d2151264e1a3 | 2014-01-05T00:00:00UTC | jane  |            | 8f1b5c6 |            | web      | yes        | v3        
 `, "output")
 }
 
@@ -112,6 +120,8 @@ func TestBuildsListLimit(t *testing.T) {
 	var b bytes.Buffer
 	cmdr := DeisCmd{WOut: &b, ConfigFile: cf}
 
+	handleReleases(t, server, "foo")
+
 	server.Mux.HandleFunc("/v2/apps/foo/builds/", func(w http.ResponseWriter, r *http.Request) {
 		testutil.SetHeaders(w)
 		fmt.Fprintf(w, `{
@@ -138,8 +148,62 @@ func TestBuildsListLimit(t *testing.T) {
 	err = cmdr.BuildsList("foo", 1)
 	assert.NoErr(t, err)
 	assert.Equal(t, b.String(), `=== foo Builds (1 of 2)
-de1bf5b5-4a72-4f94-a10c-d2a3741cdf75 2014-01-01T00:00:00UTC
+                  UUID                 |        Created         | Owner | Image | SHA | Types | Sidecars | Dockerfile | Releases  
++--------------------------------------+------------------------+-------+-------+-----+-------+----------+------------+----------+
+  de1bf5b5-4a72-4f94-a10c-d2a3741cdf75 | 2014-01-01T00:00:00UTC |       |       |     |       |          | no         |           
+`, "output")
+}
+
+func TestBuildsInfo(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	var b bytes.Buffer
+	cmdr := DeisCmd{WOut: &b, ConfigFile: cf}
+
+	handleReleases(t, server, "foo",
+		api.Release{Version: 4, Build: "c4aed81c-d1ca-4ff1-ab89-d2151264e1a3"},
+		api.Release{Version: 3, Build: "c4aed81c-d1ca-4ff1-ab89-d2151264e1a3"},
+		api.Release{Version: 2, Build: "de1bf5b5-4a72-4f94-a10c-d2a3741cdf75"})
+
+	server.Mux.HandleFunc("/v2/apps/foo/builds/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"count": 1, "results": [{
+			"created": "2014-01-05T00:00:00UTC",
+			"image": "foo/web:v2",
+			"owner": "jane",
+			"procfile": {"worker": "./work", "web": "./server"},
+			"sidecarfile": {"web": [{"name": "proxy", "image": "envoy:latest"}]},
+			"sha": "8f1b5c6a2e9d4f7b3c0a1e2d3f4a5b6c7d8e9f0a",
+			"uuid": "c4aed81c-d1ca-4ff1-ab89-d2151264e1a3"
+		}]}`)
+	})
+
+	err = cmdr.BuildsInfo("foo", "c4aed81c-d1ca-4ff1-ab89-d2151264e1a3")
+	assert.NoErr(t, err)
+	assert.Equal(t, b.String(), `=== foo Build c4aed81c-d1ca-4ff1-ab89-d2151264e1a3
+created:    2014-01-05T00:00:00UTC
+owner:      jane
+image:      foo/web:v2
+sha:        8f1b5c6a2e9d4f7b3c0a1e2d3f4a5b6c7d8e9f0a
+dockerfile: no
+releases:   v3, v4
+
+--- Procfile
+web: ./server
+worker: ./work
+
+--- Sidecarfile
+web:
+- image: envoy:latest
+  name: proxy
 `, "output")
+
+	err = cmdr.BuildsInfo("foo", "missing")
+	assert.Err(t, errors.New("Could not find build missing in app foo"), err)
 }
 
 func TestBuildsCreate(t *testing.T) {
@@ -167,7 +231,7 @@ func TestBuildsCreate(t *testing.T) {
 		fmt.Fprintf(w, "{}")
 	})
 
-	err = cmdr.BuildsCreate("enterprise", "ncc/1701:A", "", "")
+	err = cmdr.BuildsCreate("enterprise", "ncc/1701:A", "", "", 0)
 	assert.NoErr(t, err)
 	assert.Equal(t, testutil.StripProgress(b.String()), "Creating build... done\n", "output")
 
@@ -198,7 +262,7 @@ func TestBuildsCreate(t *testing.T) {
 warp: ./warp 8
 `, `web:
 - name: busybox
-  image: busybox:latest`)
+  image: busybox:latest`, 0)
 	assert.NoErr(t, err)
 	assert.Equal(t, testutil.StripProgress(b.String()), "Creating build... done\n", "output")
 
@@ -235,7 +299,7 @@ warp: ./warp 8
   image: busybox:latest`), os.ModePerm)
 	assert.NoErr(t, err)
 
-	err = cmdr.BuildsCreate("franklin", "nx/326:latest", "", "")
+	err = cmdr.BuildsCreate("franklin", "nx/326:latest", "", "", 0)
 	assert.NoErr(t, err)
 	assert.Equal(t, testutil.StripProgress(b.String()), "Creating build... done\n", "output")
 
@@ -8,7 +8,6 @@ import (
 
 	"github.com/olekukonko/tablewriter"
 
-	"github.com/deis/controller-sdk-go"
 	"github.com/deis/controller-sdk-go/certs"
 	dtime "github.com/deis/controller-sdk-go/pkg/time"
 	"github.com/deis/workflow-cli/settings"
@@ -29,10 +28,14 @@ func (d *DeisCmd) CertsList(results int, now time.Time) error {
 	}
 
 	certList, _, err := certs.List(s.Client, results)
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
+	if d.structured() {
+		return d.printStructured(certList)
+	}
+
 	if len(certList) == 0 {
 		d.Println("No certs")
 		return nil
@@ -107,7 +110,7 @@ func (d *DeisCmd) CertAdd(cert string, key string, name string) error {
 
 	d.Print("Adding SSL endpoint... ")
 	quit := progress(d.WOut)
-	err = d.doCertAdd(s.Client, cert, key, name)
+	err = d.doCertAdd(s, cert, key, name)
 	quit <- true
 	<-quit
 
@@ -119,7 +122,7 @@ func (d *DeisCmd) CertAdd(cert string, key string, name string) error {
 	return nil
 }
 
-func (d *DeisCmd) doCertAdd(c *deis.Client, cert string, key string, name string) error {
+func (d *DeisCmd) doCertAdd(s *settings.Settings, cert string, key string, name string) error {
 	certFile, err := ioutil.ReadFile(cert)
 	if err != nil {
 		return err
@@ -130,14 +133,14 @@ func (d *DeisCmd) doCertAdd(c *deis.Client, cert string, key string, name string
 		return err
 	}
 
-	_, err = certs.New(c, string(certFile), string(keyFile), name)
-	return d.checkAPICompatibility(c, err)
+	_, err = certs.New(s.Client, string(certFile), string(keyFile), name)
+	return d.checkAPICompatibility(s, err)
 }
 
 // CertRemove deletes a cert from the controller.
 func (d *DeisCmd) CertRemove(name string) error {
 	s, err := settings.Load(d.ConfigFile)
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
@@ -147,7 +150,7 @@ func (d *DeisCmd) CertRemove(name string) error {
 	err = certs.Delete(s.Client, name)
 	quit <- true
 	<-quit
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
@@ -163,10 +166,14 @@ func (d *DeisCmd) CertInfo(name string) error {
 	}
 
 	cert, err := certs.Get(s.Client, name)
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
+	if d.structured() {
+		return d.printStructured(cert)
+	}
+
 	domains := strings.Join(cert.Domains[:], ",")
 	if domains == "" {
 		domains = "No connected domains"
@@ -213,7 +220,7 @@ func (d *DeisCmd) CertAttach(name, domain string) error {
 	err = certs.Attach(s.Client, name, domain)
 	quit <- true
 	<-quit
-	if d.checkAPICompatibility(s.Client, err) == nil {
+	if d.checkAPICompatibility(s, err) == nil {
 		d.Println("done")
 	}
 
@@ -234,7 +241,7 @@ func (d *DeisCmd) CertDetach(name, domain string) error {
 	err = certs.Detach(s.Client, name, domain)
 	quit <- true
 	<-quit
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
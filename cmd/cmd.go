@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/deis/controller-sdk-go/api"
+	"github.com/deis/workflow-cli/settings"
 )
 
 // Commander is interface definition for running commands
@@ -18,33 +19,40 @@ type Commander interface {
 	AppsList(int) error
 	AppInfo(string) error
 	AppOpen(string) error
-	AppLogs(string, int, string, bool) error
-	AppRun(string, string) error
+	AppLogs(string, int, []string, bool, string, time.Time, time.Time, bool) error
+	AppRun(string, string, bool, time.Duration) error
 	AppDestroy(string, string) error
 	AppTransfer(string, string) error
+	AppApply(string, string, bool) error
+	AppExport(string, string) error
+	AppClone(string, string, string, bool, bool, bool) error
 	AutoscaleList(string) error
 	AutoscaleSet(string, string, int, int, int) error
 	AutoscaleUnset(string, string) error
 	Register(string, string, string, string, bool, bool) error
-	Login(string, string, string, bool, bool) error
+	Login(string, string, string, bool, bool, string, string, string, string) error
+	LoginSSO(string, string, string, bool, string, string, string, string) error
 	Logout() error
 	Passwd(string, string, string) error
 	Cancel(string, string, bool, bool) error
 	Whoami(bool) error
 	Regenerate(string, bool) error
 	BuildsList(string, int) error
-	BuildsCreate(string, string, string, string) error
+	BuildsInfo(string, string) error
+	BuildsCreate(string, string, string, string, time.Duration) error
+	BuildsValidate(string, string) error
 	CertsList(int, time.Time) error
 	CertAdd(string, string, string) error
 	CertRemove(string) error
 	CertInfo(string) error
 	CertAttach(string, string) error
 	CertDetach(string, string) error
-	ConfigList(string, string) error
-	ConfigSet(string, []string) error
-	ConfigUnset(string, []string) error
+	ConfigList(string, string, bool) error
+	ConfigSet(string, []string, bool, time.Duration) error
+	ConfigUnset(string, []string, bool) error
 	ConfigPull(string, bool, bool) error
 	ConfigPush(string, string) error
+	ConfigDiff(string, string, int, string, bool, bool) error
 	DomainsList(string, int) error
 	DomainsAdd(string, string) error
 	DomainsRemove(string, string) error
@@ -68,15 +76,24 @@ type Commander interface {
 	PermsList(string, bool, int) error
 	PermCreate(string, string, bool) error
 	PermDelete(string, string, bool) error
-	PsList(string, int) error
-	PsScale(string, []string) error
-	PsRestart(string, string) error
+	ProfilesList() error
+	ProfilesUse(string) error
+	ProfilesShow(string) error
+	ProfilesRename(string, string) error
+	ProfilesDelete(string) error
+	PsList(string, int, string, string, string, bool, time.Time) error
+	PsScale(string, []string, time.Duration) error
+	PsRestart(string, string, time.Duration) error
+	PsWait(string, []string, time.Duration) error
+	PsDescribe(string, string, int) error
+	PsExec(string, string, []string, bool) error
 	RegistryList(string) error
 	RegistrySet(string, []string) error
 	RegistryUnset(string, []string) error
-	ReleasesList(string, int) error
+	ReleasesList(string, int, string, string, time.Time, time.Time, int) error
 	ReleasesInfo(string, int) error
-	ReleasesRollback(string, int) error
+	ReleasesRollback(string, int, time.Duration, string, bool) error
+	ReleasesDiff(string, int, int) error
 	RoutingInfo(string) error
 	RoutingEnable(string) error
 	RoutingDisable(string) error
@@ -106,10 +123,26 @@ type Commander interface {
 // DeisCmd is an implementation of Commander.
 type DeisCmd struct {
 	ConfigFile string
+	Output     string
+	DryRun     bool
 	Warned     bool
 	WOut       io.Writer
 	WErr       io.Writer
 	WIn        io.Reader
+	// expired is set when the controller rejects the token, to tell the user to log in again.
+	// session is the settings the command used then, to log in again the same way.
+	expired *SessionExpiredError
+	session *settings.Settings
+}
+
+// ExitError is returned by commands that ran but need the CLI to exit with a non-zero code,
+// like config:diff finding differences. It isn't printed as an error.
+type ExitError struct {
+	Code int
+}
+
+func (e ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
 }
 
 // Println prints a line to an output writer.
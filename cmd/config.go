@@ -9,15 +9,19 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/deis/pkg/prettyprint"
 
 	"github.com/deis/controller-sdk-go/api"
 	"github.com/deis/controller-sdk-go/config"
+	"github.com/deis/controller-sdk-go/releases"
+	"github.com/deis/workflow-cli/settings"
 )
 
-// ConfigList lists an app's config.
-func (d *DeisCmd) ConfigList(appID string, format string) error {
+// ConfigList lists an app's config. The values of secret keys are masked unless reveal is true.
+func (d *DeisCmd) ConfigList(appID string, format string, reveal bool) error {
 	s, appID, err := load(d.ConfigFile, appID)
 
 	if err != nil {
@@ -25,10 +29,18 @@ func (d *DeisCmd) ConfigList(appID string, format string) error {
 	}
 
 	config, err := config.List(s.Client, appID)
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
+	if !reveal {
+		config.Values = maskSecrets(config.Values, secretPatterns(s))
+	}
+
+	if d.structured() {
+		return d.printStructured(config)
+	}
+
 	keys := sortKeys(config.Values)
 
 	var configOutput *bytes.Buffer = new(bytes.Buffer)
@@ -63,15 +75,22 @@ func (d *DeisCmd) ConfigList(appID string, format string) error {
 	return nil
 }
 
-// ConfigSet sets an app's config variables.
-func (d *DeisCmd) ConfigSet(appID string, configVars []string) error {
-	s, appID, err := load(d.ConfigFile, appID)
-
+// ConfigSet sets an app's config variables. If wait isn't zero, it waits up to that long for
+// the processes to be up on the new release.
+func (d *DeisCmd) ConfigSet(appID string, configVars []string, reveal bool, wait time.Duration) error {
+	configMap, err := parseConfig(configVars)
 	if err != nil {
 		return err
 	}
 
-	configMap, err := parseConfig(configVars)
+	return d.setConfig(appID, configMap, reveal, wait)
+}
+
+// setConfig sets the config values of an app and lists its config.
+func (d *DeisCmd) setConfig(appID string, configMap map[string]interface{}, reveal bool,
+	wait time.Duration) error {
+	s, appID, err := load(d.ConfigFile, appID)
+
 	if err != nil {
 		return err
 	}
@@ -99,14 +118,19 @@ to set up annotations. This functionality has been deprecated. Please prefer set
 		}
 	}
 
+	configObj := api.Config{Values: configMap}
+
+	if d.DryRun {
+		return d.dryRunConfig(s, appID, configObj, reveal)
+	}
+
 	d.Print("Creating config... ")
 
 	quit := progress(d.WOut)
-	configObj := api.Config{Values: configMap}
 	configObj, err = config.Set(s.Client, appID, configObj)
 	quit <- true
 	<-quit
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
@@ -116,21 +140,22 @@ to set up annotations. This functionality has been deprecated. Please prefer set
 		d.Print("done\n\n")
 	}
 
-	return d.ConfigList(appID, "")
+	if err = d.ConfigList(appID, "", reveal); err != nil || wait == 0 {
+		return err
+	}
+
+	d.Println()
+	return d.waitForLatestRelease(s, appID, nil, wait)
 }
 
 // ConfigUnset removes a config variable from an app.
-func (d *DeisCmd) ConfigUnset(appID string, configVars []string) error {
+func (d *DeisCmd) ConfigUnset(appID string, configVars []string, reveal bool) error {
 	s, appID, err := load(d.ConfigFile, appID)
 
 	if err != nil {
 		return err
 	}
 
-	d.Print("Removing config... ")
-
-	quit := progress(d.WOut)
-
 	configObj := api.Config{}
 
 	valuesMap := make(map[string]interface{})
@@ -141,16 +166,24 @@ func (d *DeisCmd) ConfigUnset(appID string, configVars []string) error {
 
 	configObj.Values = valuesMap
 
+	if d.DryRun {
+		return d.dryRunConfig(s, appID, configObj, reveal)
+	}
+
+	d.Print("Removing config... ")
+
+	quit := progress(d.WOut)
+
 	_, err = config.Set(s.Client, appID, configObj)
 	quit <- true
 	<-quit
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
 	d.Print("done\n\n")
 
-	return d.ConfigList(appID, "")
+	return d.ConfigList(appID, "", reveal)
 }
 
 // ConfigPull pulls an app's config to a file.
@@ -162,7 +195,7 @@ func (d *DeisCmd) ConfigPull(appID string, interactive bool, overwrite bool) err
 	}
 
 	configVars, err := config.List(s.Client, appID)
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
@@ -191,9 +224,7 @@ func (d *DeisCmd) ConfigPull(appID string, interactive bool, overwrite bool) err
 		if err != nil {
 			return err
 		}
-		localConfigVars := strings.Split(string(contents), "\n")
-
-		configMap, err := parseConfig(localConfigVars[:len(localConfigVars)-1])
+		configMap, err := parseEnvFile(contents)
 		if err != nil {
 			return err
 		}
@@ -245,24 +276,201 @@ func (d *DeisCmd) ConfigPush(appID, fileName string) error {
 		}
 	}
 
-	file := strings.Split(string(contents), "\n")
-	config := []string{}
+	configMap, err := parseEnvFile(contents)
+	if err != nil {
+		return err
+	}
+
+	return d.setConfig(appID, configMap, false, 0)
+}
 
-	for _, configVar := range file {
-		// If file has CRLF encoding, the default on windows, strip the CR
-		configVar = strings.Trim(configVar, "\r")
-		if len(configVar) > 0 {
-			config = append(config, configVar)
+// configDifference is a key whose value differs between an app and what it's compared to.
+type configDifference struct {
+	Key       string `json:"key"`
+	Op        string `json:"op"`
+	Value     string `json:"value,omitempty"`
+	Reference string `json:"reference,omitempty"`
+}
+
+func (c configDifference) String() string {
+	switch c.Op {
+	case "+":
+		return fmt.Sprintf("+ %s=%s", c.Key, c.Value)
+	case "-":
+		return fmt.Sprintf("- %s=%s", c.Key, c.Reference)
+	default:
+		return fmt.Sprintf("~ %s=%s (was %s)", c.Key, c.Value, c.Reference)
+	}
+}
+
+// diffConfig lists the keys that were added, removed or changed in values compared to
+// reference. The values of keys for which masked returns true are replaced by asterisks.
+func diffConfig(values, reference map[string]interface{}, masked func(key string) bool) []configDifference {
+	differences := []configDifference{}
+
+	changed := diffFlat("config", renderValues(values), renderValues(reference), func(string, bool) {})
+	for _, change := range changed {
+		difference := configDifference{Key: change.Key, Op: change.Op}
+
+		switch change.Op {
+		case "+":
+			difference.Value = change.New
+		case "-":
+			difference.Reference = manifestValue(reference[change.Key])
+		default:
+			difference.Value = change.New
+			difference.Reference = change.Old
 		}
+
+		if masked(change.Key) {
+			if difference.Value != "" {
+				difference.Value = secretMask
+			}
+			if difference.Reference != "" {
+				difference.Reference = secretMask
+			}
+		}
+
+		differences = append(differences, difference)
+	}
+
+	return differences
+}
+
+// releasesChangingConfig returns the releases of an app after version that changed its
+// config, oldest first.
+func (d *DeisCmd) releasesChangingConfig(s *settings.Settings, appID string, version int) ([]api.Release, error) {
+	appReleases, err := d.allReleases(s, appID)
+	if err != nil {
+		return nil, err
 	}
 
-	return d.ConfigSet(appID, config)
+	sort.Slice(appReleases, func(i, j int) bool { return appReleases[i].Version < appReleases[j].Version })
+
+	changed := []api.Release{}
+	previous := ""
+	for _, release := range appReleases {
+		if release.Version > version && release.Config != previous {
+			changed = append(changed, release)
+		}
+		previous = release.Config
+	}
+
+	return changed, nil
+}
+
+// ConfigDiff compares an app's config against another app, a release, or a local env file,
+// and returns an ExitError if they differ. All values are masked if mask is true, and the
+// values of secret keys are masked unless reveal is true.
+func (d *DeisCmd) ConfigDiff(appID, otherApp string, version int, fileName string, mask, reveal bool) error {
+	s, appID, err := load(d.ConfigFile, appID)
+
+	if err != nil {
+		return err
+	}
+
+	current, err := config.List(s.Client, appID)
+	if d.checkAPICompatibility(s, err) != nil {
+		return err
+	}
+
+	var reference map[string]interface{}
+	var against string
+
+	switch {
+	case otherApp != "":
+		otherConfig, err := config.List(s.Client, otherApp)
+		if d.checkAPICompatibility(s, err) != nil {
+			return err
+		}
+		reference = otherConfig.Values
+		against = otherApp
+	case version >= 0:
+		// The controller only serves an app's current config, so releases are compared by
+		// config UUID and the releases that changed it are listed.
+		return d.configDiffRelease(s, appID, version, current)
+	default:
+		contents, err := ioutil.ReadFile(fileName)
+		if err != nil {
+			return err
+		}
+		if reference, err = parseEnvFile(contents); err != nil {
+			return err
+		}
+		against = fileName
+	}
+
+	patterns := secretPatterns(s)
+	differences := diffConfig(current.Values, reference, func(key string) bool {
+		return mask || (!reveal && isSecretKey(key, patterns))
+	})
+
+	if d.structured() {
+		if err = d.printStructured(differences); err != nil {
+			return err
+		}
+	} else {
+		d.Printf("=== %s Config Diff against %s\n", appID, against)
+		if len(differences) == 0 {
+			d.Println("No differences.")
+		}
+		for _, difference := range differences {
+			d.Println(difference)
+		}
+	}
+
+	if len(differences) > 0 {
+		return ExitError{Code: 1}
+	}
+
+	return nil
+}
+
+func (d *DeisCmd) configDiffRelease(s *settings.Settings, appID string, version int, current api.Config) error {
+	release, err := releases.Get(s.Client, appID, version)
+	if d.checkAPICompatibility(s, err) != nil {
+		return err
+	}
+
+	changed := []api.Release{}
+	if release.Config != current.UUID {
+		if changed, err = d.releasesChangingConfig(s, appID, version); err != nil {
+			return err
+		}
+	}
+
+	if d.structured() {
+		if err = d.printStructured(changed); err != nil {
+			return err
+		}
+	} else {
+		d.Printf("=== %s Config Diff against v%d\n", appID, version)
+		if len(changed) == 0 {
+			d.Println("No differences.")
+		} else {
+			d.Printf("The config was changed by %d release(s) since v%d:\n", len(changed), version)
+		}
+
+		w := new(tabwriter.Writer)
+		w.Init(d.WOut, 0, 8, 1, '\t', 0)
+		for _, r := range changed {
+			fmt.Fprintf(w, "v%d\t%s\t%s\n", r.Version, r.Created, r.Summary)
+		}
+		w.Flush()
+
+		if len(changed) > 0 {
+			d.Println("Only the current config is kept, so the values can't be compared.")
+		}
+	}
+
+	// Without the values, there's no drift to report, so this doesn't exit with status 1.
+	return nil
 }
 
 func parseConfig(configVars []string) (map[string]interface{}, error) {
 	configMap := make(map[string]interface{})
 
-	regex := regexp.MustCompile(`^([A-z_]+[A-z0-9_]*)=([\s\S]*)$`)
+	regex := regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)=([\s\S]*)$`)
 	for _, config := range configVars {
 		// Skip config that starts with an comment
 		if config[0] == '#' {
@@ -311,17 +519,6 @@ func parseSSHKey(value string) (string, error) {
 	return "", fmt.Errorf("Could not parse SSH private key:\n %s", value)
 }
 
-func formatConfig(configVars map[string]interface{}) string {
-	var formattedConfig string
-
-	keys := sortKeys(configVars)
-	for _, key := range keys {
-		formattedConfig += fmt.Sprintf("%s=%v\n", key, configVars[key])
-	}
-
-	return formattedConfig
-}
-
 func sortKeys(kv map[string]interface{}) []string {
 	var keys []string
 	for k := range kv {
@@ -0,0 +1,306 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/deis/controller-sdk-go/api"
+	"github.com/deis/controller-sdk-go/apps"
+	"github.com/deis/controller-sdk-go/config"
+	"github.com/deis/controller-sdk-go/ps"
+	"k8s.io/api/core/v1"
+)
+
+// routableTypes are the process types that receive requests from the router.
+var routableTypes = map[string]bool{"web": true, "cmd": true}
+
+var (
+	memoryRegex = regexp.MustCompile(`^([0-9.]+)([bkmgBKMG]?)[bB]?$`)
+	cpuRegex    = regexp.MustCompile(`^([0-9.]+)(m?)$`)
+)
+
+// processDescription is everything the controller exposes about a process.
+type processDescription struct {
+	Process      api.Pods                  `json:"process"`
+	Memory       string                    `json:"memory,omitempty"`
+	CPU          string                    `json:"cpu,omitempty"`
+	Healthchecks api.Healthchecks          `json:"healthchecks,omitempty"`
+	Tolerations  map[string]*v1.Toleration `json:"tolerations,omitempty"`
+	Logs         []logRecord               `json:"logs"`
+	Causes       []string                  `json:"causes"`
+}
+
+// PsDescribe prints what the controller knows about a process, its recent log lines, and the
+// likely causes of it not running.
+func (d *DeisCmd) PsDescribe(appID, name string, lines int) error {
+	s, appID, err := load(d.ConfigFile, appID)
+	if err != nil {
+		return err
+	}
+
+	processes, _, err := ps.List(s.Client, appID, s.Limit)
+	if d.checkAPICompatibility(s, err) != nil {
+		return err
+	}
+
+	description := processDescription{}
+	found := false
+	for _, process := range processes {
+		if process.Name == name {
+			description.Process = process
+			found = true
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("Could not find process %s in app %s", name, appID)
+	}
+
+	appConfig, err := config.List(s.Client, appID)
+	if d.checkAPICompatibility(s, err) != nil {
+		return err
+	}
+
+	psType := description.Process.Type
+	description.Memory = limitValue(appConfig.Memory[psType])
+	description.CPU = limitValue(appConfig.CPU[psType])
+	if healthchecks := appConfig.Healthcheck[psType]; healthchecks != nil {
+		description.Healthchecks = *healthchecks
+	}
+	description.Tolerations = appConfig.Tolerations[psType]
+
+	logs, err := apps.Logs(s.Client, appID, lines, psType)
+	if d.checkAPICompatibility(s, err) != nil {
+		return err
+	}
+
+	description.Logs = []logRecord{}
+	for _, line := range splitLogs(logs) {
+		if record := parseLogLine(appID, line); record.Pod == name {
+			description.Logs = append(description.Logs, record)
+		}
+	}
+
+	description.Causes = likelyCauses(description)
+
+	if d.structured() {
+		return d.printStructured(description)
+	}
+
+	d.printProcessDescription(description)
+	return nil
+}
+
+func (d *DeisCmd) printProcessDescription(description processDescription) {
+	process := description.Process
+
+	d.Printf("=== %s Process\n", process.Name)
+	d.Println("type:    ", process.Type)
+	d.Println("state:   ", process.State)
+	d.Println("release: ", process.Release)
+	d.Println("started: ", safeGetTime(process.Started))
+
+	d.Println("\n--- Limits")
+	d.Println("memory:  ", unlimited(description.Memory))
+	d.Println("cpu:     ", unlimited(description.CPU))
+
+	d.Println()
+	d.printHealthCheck(description.Healthchecks)
+
+	d.Println("\n--- Tolerations")
+	if len(description.Tolerations) == 0 {
+		d.Println("No tolerations configured.")
+	}
+	for _, identifier := range sortedTolerations(description.Tolerations) {
+		toleration := description.Tolerations[identifier]
+		d.Printf("%s: Key=%s,Operator=%s,Value=%s,Effect=%s\n", identifier, toleration.Key,
+			toleration.Operator, toleration.Value, toleration.Effect)
+	}
+
+	d.Println("\n--- Recent Logs")
+	if len(description.Logs) == 0 {
+		d.Println("No recent log lines.")
+	}
+	for _, record := range description.Logs {
+		d.Println(record.line)
+	}
+
+	d.Println("\n--- Likely Causes")
+	if len(description.Causes) == 0 {
+		d.Println("None found.")
+	}
+	for _, cause := range description.Causes {
+		d.Println("-", cause)
+	}
+}
+
+// pendingPodStates are the states of processes that haven't started running yet.
+var pendingPodStates = map[string]bool{"starting": true}
+
+// outOfMemoryRegex matches the log lines of processes that ran out of memory.
+var outOfMemoryRegex = regexp.MustCompile(`(?i)out of memory|cannot allocate memory|OOMKilled`)
+
+// likelyCauses suggests why a process failed or hasn't started, from its state, its recent
+// logs and its settings. Processes in any other state get no suggestions, and settings are
+// only blamed when the state or the logs point at them.
+func likelyCauses(description processDescription) []string {
+	process := description.Process
+	psType := process.Type
+	causes := []string{}
+
+	failed, pending := failedPodStates[process.State], pendingPodStates[process.State]
+	if !failed && !pending {
+		return causes
+	}
+
+	memoryRequest, memoryLimit := splitLimit(description.Memory)
+	if request, limit, ok := compareLimits(memoryRequest, memoryLimit, memoryBytes); ok && limit < request {
+		causes = append(causes, fmt.Sprintf(
+			"The memory limit of %s (%s) is below its request (%s), see 'deis limits:set'.",
+			psType, memoryLimit, memoryRequest))
+	}
+
+	cpuRequest, cpuLimit := splitLimit(description.CPU)
+	if request, limit, ok := compareLimits(cpuRequest, cpuLimit, cpuCores); ok && limit < request {
+		causes = append(causes, fmt.Sprintf(
+			"The CPU limit of %s (%s) is below its request (%s), see 'deis limits:set --cpu'.",
+			psType, cpuLimit, cpuRequest))
+	}
+
+	if failed && memoryLimit != "" && loggedOutOfMemory(description.Logs) {
+		causes = append(causes, fmt.Sprintf(
+			"Processes of %s are killed when they use more memory than their limit (%s), see 'deis limits'.",
+			psType, memoryLimit))
+	}
+
+	_, hasLiveness := description.Healthchecks["livenessProbe"]
+	readiness, hasReadiness := description.Healthchecks["readinessProbe"]
+
+	if failed && hasLiveness {
+		causes = append(causes, fmt.Sprintf(
+			"The liveness probe of %s restarts processes it finds unhealthy, check its settings with 'deis healthchecks'.",
+			psType))
+	}
+
+	// Only the router waits for the readiness probe, other types are started regardless.
+	if pending && routableTypes[psType] && hasReadiness && readiness != nil {
+		causes = append(causes, fmt.Sprintf(
+			"The readiness probe of %s hasn't passed yet, so the router doesn't send it requests, it waits %ds before its first check.",
+			psType, readiness.InitialDelaySeconds))
+	}
+
+	// The controller doesn't tell why a pod isn't scheduled, but one that hasn't logged anything
+	// may not be running yet, and tainted nodes would keep it off without a toleration.
+	if pending && len(description.Tolerations) == 0 && len(description.Logs) == 0 {
+		causes = append(causes, fmt.Sprintf(
+			"%s hasn't logged anything yet, it may not be scheduled if all nodes are tainted, as %s has no tolerations, see 'deis toleration:set'.",
+			process.Name, psType))
+	}
+
+	if failed && len(description.Logs) == 0 {
+		causes = append(causes, fmt.Sprintf(
+			"%s hasn't logged anything recently, so it may fail before its command starts, check its image and command.",
+			process.Name))
+	}
+
+	return causes
+}
+
+// loggedOutOfMemory returns whether any of the log lines of a process say it ran out of memory.
+func loggedOutOfMemory(logs []logRecord) bool {
+	for _, record := range logs {
+		if outOfMemoryRegex.MatchString(record.Message) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// limitValue returns a limit from the config of an app, or an empty string if there is none.
+func limitValue(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%v", value)
+}
+
+// unlimited returns a limit, or "Unlimited" if there is none.
+func unlimited(limit string) string {
+	if limit == "" {
+		return "Unlimited"
+	}
+
+	return limit
+}
+
+// splitLimit splits a limit such as 64M/128M into its request and its limit. A single value
+// is the limit.
+func splitLimit(value string) (string, string) {
+	if parts := strings.SplitN(value, "/", 2); len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+
+	return "", value
+}
+
+// compareLimits parses a request and a limit, and returns false if either can't be parsed.
+func compareLimits(request, limit string, parse func(string) (float64, bool)) (float64, float64, bool) {
+	requestValue, requestOk := parse(request)
+	limitValue, limitOk := parse(limit)
+
+	return requestValue, limitValue, requestOk && limitOk
+}
+
+// memoryBytes parses an amount of memory, such as 512M or 2G.
+func memoryBytes(value string) (float64, bool) {
+	captures := memoryRegex.FindStringSubmatch(value)
+	if captures == nil {
+		return 0, false
+	}
+
+	amount, err := strconv.ParseFloat(captures[1], 64)
+	if err != nil {
+		return 0, false
+	}
+
+	exponent := strings.Index("bkmg", strings.ToLower(captures[2]))
+	for i := 0; i < exponent; i++ {
+		amount *= 1024
+	}
+
+	return amount, true
+}
+
+// cpuCores parses an amount of CPU, such as 250m or 1.
+func cpuCores(value string) (float64, bool) {
+	captures := cpuRegex.FindStringSubmatch(value)
+	if captures == nil {
+		return 0, false
+	}
+
+	amount, err := strconv.ParseFloat(captures[1], 64)
+	if err != nil {
+		return 0, false
+	}
+
+	if captures[2] == "m" {
+		amount /= 1000
+	}
+
+	return amount, true
+}
+
+func sortedTolerations(tolerations map[string]*v1.Toleration) []string {
+	identifiers := make([]string, 0, len(tolerations))
+	for identifier := range tolerations {
+		identifiers = append(identifiers, identifier)
+	}
+	sort.Strings(identifiers)
+
+	return identifiers
+}
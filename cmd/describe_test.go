@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/arschles/assert"
+	"github.com/deis/controller-sdk-go/api"
+	"github.com/deis/workflow-cli/pkg/testutil"
+)
+
+func TestMemoryBytes(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]float64{
+		"512":  512,
+		"1K":   1024,
+		"128M": 128 * 1024 * 1024,
+		"2g":   2 * 1024 * 1024 * 1024,
+		"64MB": 64 * 1024 * 1024,
+	}
+
+	for value, expected := range cases {
+		actual, ok := memoryBytes(value)
+		assert.Equal(t, ok, true, value)
+		assert.Equal(t, actual, expected, value)
+	}
+
+	_, ok := memoryBytes("lots")
+	assert.Equal(t, ok, false, "invalid memory")
+}
+
+func TestCPUCores(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]float64{"1": 1, "0.5": 0.5, "250m": 0.25}
+
+	for value, expected := range cases {
+		actual, ok := cpuCores(value)
+		assert.Equal(t, ok, true, value)
+		assert.Equal(t, actual, expected, value)
+	}
+}
+
+func TestLikelyCauses(t *testing.T) {
+	t.Parallel()
+
+	description := processDescription{
+		Process: api.Pods{Name: "foo-web-4084101150-c871y", Type: "web", State: "crashed"},
+		Memory:  "256M/128M",
+		CPU:     "500m/1",
+		Healthchecks: api.Healthchecks{
+			"livenessProbe": &api.Healthcheck{InitialDelaySeconds: 50},
+		},
+	}
+
+	assert.Equal(t, likelyCauses(description), []string{
+		"The memory limit of web (128M) is below its request (256M), see 'deis limits:set'.",
+		"The liveness probe of web restarts processes it finds unhealthy, check its settings with 'deis healthchecks'.",
+		"foo-web-4084101150-c871y hasn't logged anything recently, so it may fail before its command starts, check its image and command.",
+	}, "causes")
+
+	// The memory limit is only blamed when the logs say the process ran out of memory.
+	description = processDescription{
+		Process: api.Pods{Name: "foo-worker-4084101150-c871y", Type: "worker", State: "crashed"},
+		Memory:  "128M",
+		Logs:    []logRecord{{Message: "fatal error: runtime: out of memory"}},
+	}
+	assert.Equal(t, likelyCauses(description), []string{
+		"Processes of worker are killed when they use more memory than their limit (128M), see 'deis limits'.",
+	}, "causes")
+
+	description.Logs = []logRecord{{Message: "panic: nil pointer dereference"}}
+	assert.Equal(t, likelyCauses(description), []string{}, "causes")
+
+	description = processDescription{
+		Process: api.Pods{Name: "foo-web-4084101150-x9z8y", Type: "web", State: "starting"},
+	}
+	assert.Equal(t, likelyCauses(description), []string{
+		"foo-web-4084101150-x9z8y hasn't logged anything yet, it may not be scheduled if all nodes are tainted, as web has no tolerations, see 'deis toleration:set'.",
+	}, "causes")
+
+	// The router only waits for the readiness probe of the types it sends requests to.
+	description.Healthchecks = api.Healthchecks{
+		"readinessProbe": &api.Healthcheck{InitialDelaySeconds: 30},
+	}
+	description.Logs = []logRecord{{Message: "connecting to database"}}
+	assert.Equal(t, likelyCauses(description), []string{
+		"The readiness probe of web hasn't passed yet, so the router doesn't send it requests, it waits 30s before its first check.",
+	}, "causes")
+
+	description.Process.Type = "worker"
+	assert.Equal(t, likelyCauses(description), []string{}, "causes")
+}
+
+func TestLikelyCausesHealthy(t *testing.T) {
+	t.Parallel()
+
+	// Settings that would be suspicious for a failing process aren't reported for a healthy one.
+	for _, state := range []string{"up", "terminating", "down"} {
+		description := processDescription{
+			Process: api.Pods{Name: "foo-web-4084101150-c871y", Type: "web", State: state},
+			Memory:  "256M/128M",
+			CPU:     "500m/1",
+			Healthchecks: api.Healthchecks{
+				"livenessProbe": &api.Healthcheck{InitialDelaySeconds: 50},
+			},
+		}
+		assert.Equal(t, likelyCauses(description), []string{}, state)
+	}
+}
+
+func TestPsDescribe(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	server.Mux.HandleFunc("/v2/apps/foo/pods/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"count": 2, "results": [
+			{"release": "v3", "type": "web", "name": "foo-web-4084101150-c871y", "state": "up", "started": null},
+			{"release": "v3", "type": "web", "name": "foo-web-4084101150-x9z8y", "state": "starting",
+			 "started": "2016-02-13T00:47:52"}
+		]}`)
+	})
+
+	server.Mux.HandleFunc("/v2/apps/foo/config/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{
+			"app": "foo",
+			"memory": {"web": "128M"},
+			"healthcheck": {"web": {"readinessProbe": {"initialDelaySeconds": 30, "timeoutSeconds": 5,
+				"periodSeconds": 10, "successThreshold": 1, "failureThreshold": 3,
+				"httpGet": {"path": "/health", "port": 5000}}}},
+			"tolerations": {"web": {"spot": {"key": "spot", "operator": "Exists", "effect": "NoSchedule"}}}
+		}`)
+	})
+
+	server.Mux.HandleFunc("/v2/apps/foo/logs", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprint(w, `"2017-03-10T16:00:00UTC foo[foo-web-4084101150-c871y]: up and running\n`+
+			`2017-03-10T16:01:00UTC foo[foo-web-4084101150-x9z8y]: connecting to database\n"`)
+	})
+
+	var b bytes.Buffer
+	cmdr := DeisCmd{WOut: &b, ConfigFile: cf}
+
+	err = cmdr.PsDescribe("foo", "foo-web-4084101150-x9z8y", 100)
+	assert.NoErr(t, err)
+	assert.Equal(t, b.String(), `=== foo-web-4084101150-x9z8y Process
+type:     web
+state:    starting
+release:  v3
+started:  13 Feb 2016
+
+--- Limits
+memory:   128M
+cpu:      Unlimited
+
+--- Liveness
+No liveness probe configured.
+
+--- Readiness
+Initial Delay (seconds): 30
+Timeout (seconds): 5
+Period (seconds): 10
+Success Threshold: 1
+Failure Threshold: 3
+Exec Probe: N/A
+HTTP GET Probe: Path="/health" Port=5000 HTTPHeaders=[]
+TCP Socket Probe: N/A
+
+--- Tolerations
+spot: Key=spot,Operator=Exists,Value=,Effect=NoSchedule
+
+--- Recent Logs
+2017-03-10T16:01:00UTC foo[foo-web-4084101150-x9z8y]: connecting to database
+
+--- Likely Causes
+- The readiness probe of web hasn't passed yet, so the router doesn't send it requests, it waits 30s before its first check.
+`, "output")
+
+	b.Reset()
+	err = cmdr.PsDescribe("foo", "foo-web-4084101150-c871y", 100)
+	assert.NoErr(t, err)
+	assert.Equal(t, strings.HasSuffix(b.String(), "--- Likely Causes\nNone found.\n"), true, "healthy causes")
+
+	err = cmdr.PsDescribe("foo", "foo-web-ghost", 100)
+	assert.Err(t, errors.New("Could not find process foo-web-ghost in app foo"), err)
+}
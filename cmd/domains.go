@@ -1,6 +1,9 @@
 package cmd
 
-import "github.com/deis/controller-sdk-go/domains"
+import (
+	"github.com/deis/controller-sdk-go/api"
+	"github.com/deis/controller-sdk-go/domains"
+)
 
 // DomainsList lists domains registered with an app.
 func (d *DeisCmd) DomainsList(appID string, results int) error {
@@ -15,10 +18,14 @@ func (d *DeisCmd) DomainsList(appID string, results int) error {
 	}
 
 	domains, count, err := domains.List(s.Client, appID, results)
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
+	if d.structured() {
+		return d.printStructured(domains)
+	}
+
 	d.Printf("=== %s Domains%s", appID, limitCount(len(domains), count))
 
 	for _, domain := range domains {
@@ -35,13 +42,18 @@ func (d *DeisCmd) DomainsAdd(appID, domain string) error {
 		return err
 	}
 
+	if d.DryRun {
+		return d.dryRun(appID, "add domain", api.DomainCreateRequest{Domain: domain},
+			[]manifestChange{{Op: "+", Section: "domains", Key: domain}})
+	}
+
 	d.Printf("Adding %s to %s... ", domain, appID)
 
 	quit := progress(d.WOut)
 	_, err = domains.New(s.Client, appID, domain)
 	quit <- true
 	<-quit
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
@@ -57,13 +69,17 @@ func (d *DeisCmd) DomainsRemove(appID, domain string) error {
 		return err
 	}
 
+	if d.DryRun {
+		return d.dryRun(appID, "remove domain", nil, []manifestChange{{Op: "-", Section: "domains", Key: domain}})
+	}
+
 	d.Printf("Removing %s from %s... ", domain, appID)
 
 	quit := progress(d.WOut)
 	err = domains.Delete(s.Client, appID, domain)
 	quit <- true
 	<-quit
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
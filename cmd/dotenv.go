@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	envKeyRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	// envUnquotedRegex matches the values that are written without quotes.
+	envUnquotedRegex = regexp.MustCompile(`^[A-Za-z0-9_./:@,+=%-]*$`)
+	pemBeginRegex    = regexp.MustCompile(`^-----BEGIN [A-Z0-9 ]+-----`)
+	pemEndRegex      = regexp.MustCompile(`-----END [A-Z0-9 ]+-----$`)
+)
+
+// envEscapes are the escape sequences understood in double quoted values.
+var envEscapes = map[byte]string{
+	'n':  "\n",
+	'r':  "\r",
+	't':  "\t",
+	'"':  `"`,
+	'\\': `\`,
+	'$':  "$",
+}
+
+// parseEnvFile parses the contents of an env file, like the ones used by config:push.
+//
+// Lines may start with "export". Values may be single quoted, taken literally, or double
+// quoted, where \n, \r, \t, \", \\ and \$ are unescaped. Quoted values may span several
+// lines, and so may unquoted PEM blocks, such as private keys. Comments start with a "#" at
+// the start of a line or after whitespace following an unquoted or quoted value.
+func parseEnvFile(contents []byte) (map[string]interface{}, error) {
+	configMap := make(map[string]interface{})
+
+	lines := strings.Split(strings.Replace(string(contents), "\r\n", "\n", -1), "\n")
+
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		number := i + 1
+
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		if strings.HasPrefix(line, "export ") || strings.HasPrefix(line, "export\t") {
+			line = strings.TrimSpace(line[len("export"):])
+		}
+
+		sep := strings.Index(line, "=")
+		if sep < 0 || !envKeyRegex.MatchString(strings.TrimSpace(line[:sep])) {
+			return nil, fmt.Errorf("line %d: '%s' does not match the pattern 'key=var', ex: MODE=test",
+				number, line)
+		}
+
+		key := strings.TrimSpace(line[:sep])
+		rest := strings.TrimLeft(line[sep+1:], " \t")
+
+		var value string
+
+		switch {
+		case strings.HasPrefix(rest, `"`) || strings.HasPrefix(rest, "'"):
+			var consumed int
+			var err error
+			value, consumed, err = parseQuotedValue(rest, lines[i+1:])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s %v", number, key, err)
+			}
+			i += consumed
+		case pemBeginRegex.MatchString(rest) && !pemEndRegex.MatchString(rest):
+			block := []string{rest}
+			for !pemEndRegex.MatchString(block[len(block)-1]) {
+				i++
+				if i >= len(lines) {
+					return nil, fmt.Errorf("line %d: %s has no end of PEM block", number, key)
+				}
+				block = append(block, strings.TrimRight(lines[i], " \t"))
+			}
+			value = strings.Join(block, "\n")
+		default:
+			value = stripEnvComment(rest)
+		}
+
+		configMap[key] = value
+	}
+
+	return configMap, nil
+}
+
+// parseQuotedValue parses a value starting with a quote. If it isn't closed on the first
+// line, following lines are read, and the number of them consumed is returned.
+func parseQuotedValue(first string, following []string) (string, int, error) {
+	quote := first[0]
+	text := first[1:]
+	consumed := 0
+
+	var value strings.Builder
+
+	for {
+		for j := 0; j < len(text); j++ {
+			c := text[j]
+
+			if c == quote {
+				trailing := strings.TrimSpace(text[j+1:])
+				if trailing != "" && trailing[0] != '#' {
+					return "", 0, fmt.Errorf("has unexpected text after its closing quote: %s", trailing)
+				}
+				return value.String(), consumed, nil
+			}
+
+			if quote == '"' && c == '\\' && j+1 < len(text) {
+				if unescaped, ok := envEscapes[text[j+1]]; ok {
+					value.WriteString(unescaped)
+					j++
+					continue
+				}
+			}
+
+			value.WriteByte(c)
+		}
+
+		if consumed >= len(following) {
+			return "", 0, fmt.Errorf("has no closing quote")
+		}
+
+		value.WriteByte('\n')
+		text = following[consumed]
+		consumed++
+	}
+}
+
+// stripEnvComment removes an inline comment and trailing whitespace from an unquoted value.
+func stripEnvComment(value string) string {
+	for i := 1; i < len(value); i++ {
+		if value[i] == '#' && (value[i-1] == ' ' || value[i-1] == '\t') {
+			value = value[:i]
+			break
+		}
+	}
+
+	return strings.TrimRight(value, " \t")
+}
+
+// quoteEnvValue quotes a value so parseEnvFile reads it back unchanged. Values made of
+// common characters are left as is.
+func quoteEnvValue(value string) string {
+	if envUnquotedRegex.MatchString(value) {
+		return value
+	}
+
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "\n", `\n`, "\r", `\r`)
+	return `"` + replacer.Replace(value) + `"`
+}
+
+// formatConfig writes config values in the format read by parseEnvFile.
+func formatConfig(configVars map[string]interface{}) string {
+	var formattedConfig string
+
+	keys := sortKeys(configVars)
+	for _, key := range keys {
+		formattedConfig += fmt.Sprintf("%s=%s\n", key, quoteEnvValue(fmt.Sprintf("%v", configVars[key])))
+	}
+
+	return formattedConfig
+}
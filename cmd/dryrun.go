@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/deis/controller-sdk-go/api"
+	"github.com/deis/controller-sdk-go/appsettings"
+	"github.com/deis/controller-sdk-go/config"
+	"github.com/deis/controller-sdk-go/tls"
+	"github.com/deis/controller-sdk-go/whitelist"
+	"github.com/deis/workflow-cli/settings"
+)
+
+// payloadDepth is how deep each payload field is compared. Deeper values are replaced as a
+// whole by the controller, so they are shown as a single change.
+var payloadDepth = map[string]int{
+	"values":      1,
+	"memory":      1,
+	"cpu":         1,
+	"tags":        1,
+	"registry":    1,
+	"healthcheck": 2,
+	"tolerations": 2,
+	"annotations": 2,
+	"label":       1,
+	"autoscale":   1,
+}
+
+// dryRunOutput is what --dry-run prints with --output=json or --output=yaml.
+type dryRunOutput struct {
+	App     string           `json:"app"`
+	Action  string           `json:"action"`
+	Payload interface{}      `json:"payload"`
+	Changes []manifestChange `json:"changes,omitempty"`
+}
+
+// toGeneric converts a value to the maps, slices and scalars it is encoded to in JSON.
+func toGeneric(v interface{}) (interface{}, error) {
+	out, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var generic interface{}
+	err = json.Unmarshal(out, &generic)
+	return generic, err
+}
+
+func renderGeneric(v interface{}) string {
+	switch value := v.(type) {
+	case map[string]interface{}, []interface{}:
+		return jsonValue(value)
+	default:
+		return manifestValue(value)
+	}
+}
+
+// diffPayload compares a payload with the current value it would update. Keys set to nil in
+// the payload are unset by the controller, keys missing from it are left alone.
+func diffPayload(path []string, payload, current interface{}, depth int) []manifestChange {
+	payloadMap, isMap := payload.(map[string]interface{})
+	if depth > 0 && isMap {
+		currentMap, _ := current.(map[string]interface{})
+
+		keys := make([]string, 0, len(payloadMap))
+		for k := range payloadMap {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		var changes []manifestChange
+		for _, k := range keys {
+			changes = append(changes, diffPayload(append(path, k), payloadMap[k], currentMap[k], depth-1)...)
+		}
+		return changes
+	}
+
+	change := manifestChange{Section: path[0], Key: strings.Join(path[1:], " ")}
+
+	switch {
+	case payload == nil:
+		change.Op = "-"
+		if current != nil {
+			change.Old = renderGeneric(current)
+		}
+	case current == nil:
+		change.Op = "+"
+		change.New = renderGeneric(payload)
+	case renderGeneric(payload) == renderGeneric(current):
+		return nil
+	default:
+		change.Op = "~"
+		change.New = renderGeneric(payload)
+		change.Old = renderGeneric(current)
+	}
+
+	return []manifestChange{change}
+}
+
+// payloadChanges lists what sending payload would change in current. Both must encode to
+// JSON objects, such as api.Config or api.AppSettings.
+func payloadChanges(payload, current interface{}) ([]manifestChange, error) {
+	genericPayload, err := toGeneric(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	genericCurrent, err := toGeneric(current)
+	if err != nil {
+		return nil, err
+	}
+
+	payloadMap, _ := genericPayload.(map[string]interface{})
+	currentMap, _ := genericCurrent.(map[string]interface{})
+
+	fields := make([]string, 0, len(payloadMap))
+	for field := range payloadMap {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	changes := []manifestChange{}
+	for _, field := range fields {
+		changes = append(changes, diffPayload([]string{field}, payloadMap[field], currentMap[field], payloadDepth[field])...)
+	}
+
+	return changes, nil
+}
+
+// dryRun prints the payload a command would send and the changes it would make, instead of
+// sending it. changes may be nil if they can't be worked out.
+func (d *DeisCmd) dryRun(appID, action string, payload interface{}, changes []manifestChange) error {
+	if d.structured() {
+		return d.printStructured(dryRunOutput{App: appID, Action: action, Payload: payload, Changes: changes})
+	}
+
+	d.Printf("=== %s Dry Run: %s\n", appID, action)
+
+	if payload != nil {
+		out, err := json.MarshalIndent(payload, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		d.Println("Payload:")
+		d.Println(string(out))
+	}
+
+	if changes != nil {
+		if payload != nil {
+			d.Println()
+		}
+		d.Println("Changes:")
+		if len(changes) == 0 {
+			d.Println("None, the app already has these values.")
+		}
+		for _, change := range changes {
+			d.Println(change)
+		}
+	}
+
+	d.Println()
+	d.Println("Nothing was sent to the controller, as --dry-run was given.")
+	return nil
+}
+
+// dryRunConfig shows what a config update would change. The values of secret keys are masked
+// unless reveal is true.
+func (d *DeisCmd) dryRunConfig(s *settings.Settings, appID string, payload api.Config, reveal bool) error {
+	current, err := config.List(s.Client, appID)
+	if d.checkAPICompatibility(s, err) != nil {
+		return err
+	}
+
+	changes, err := payloadChanges(payload, current)
+	if err != nil {
+		return err
+	}
+
+	if !reveal {
+		patterns := secretPatterns(s)
+		if payload.Values != nil {
+			payload.Values = maskSecrets(payload.Values, patterns)
+		}
+		changes = maskChanges(changes, "values", patterns)
+	}
+
+	return d.dryRun(appID, "update config", payload, changes)
+}
+
+// dryRunWhitelist shows what adding or removing whitelist addresses would change.
+func (d *DeisCmd) dryRunWhitelist(s *settings.Settings, appID string, addresses []string, remove bool) error {
+	current, err := whitelist.List(s.Client, appID)
+	if d.checkAPICompatibility(s, err) != nil {
+		return err
+	}
+
+	existing := renderStrings(current.Addresses)
+	changes := []manifestChange{}
+	for _, address := range addresses {
+		_, found := existing[address]
+		if remove && found {
+			changes = append(changes, manifestChange{Op: "-", Section: "whitelist", Key: address})
+		} else if !remove && !found {
+			changes = append(changes, manifestChange{Op: "+", Section: "whitelist", Key: address})
+		}
+	}
+
+	action := "add to whitelist"
+	if remove {
+		action = "remove from whitelist"
+	}
+
+	return d.dryRun(appID, action, api.Whitelist{Addresses: addresses}, changes)
+}
+
+// dryRunTLS shows what enabling or disabling https-only requests would change.
+func (d *DeisCmd) dryRunTLS(s *settings.Settings, appID string, enforced bool) error {
+	current, err := tls.Info(s.Client, appID)
+	if d.checkAPICompatibility(s, err) != nil {
+		return err
+	}
+
+	payload := api.TLS{HTTPSEnforced: &enforced}
+	changes, err := payloadChanges(payload, current)
+	if err != nil {
+		return err
+	}
+
+	return d.dryRun(appID, "update tls", payload, changes)
+}
+
+// dryRunAppSettings shows what an app settings update would change.
+func (d *DeisCmd) dryRunAppSettings(s *settings.Settings, appID string, payload api.AppSettings) error {
+	current, err := appsettings.List(s.Client, appID)
+	if d.checkAPICompatibility(s, err) != nil {
+		return err
+	}
+
+	changes, err := payloadChanges(payload, current)
+	if err != nil {
+		return err
+	}
+
+	return d.dryRun(appID, "update settings", payload, changes)
+}
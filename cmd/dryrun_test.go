@@ -0,0 +1,245 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/arschles/assert"
+	"github.com/deis/controller-sdk-go/api"
+	"github.com/deis/workflow-cli/pkg/testutil"
+)
+
+func TestPayloadChanges(t *testing.T) {
+	t.Parallel()
+
+	current := api.Config{
+		Values: map[string]interface{}{"PORT": "4000", "OLD": "x"},
+		Memory: map[string]interface{}{"web": "512M"},
+		Healthcheck: map[string]*api.Healthchecks{
+			"web": {"livenessProbe": &api.Healthcheck{InitialDelaySeconds: 5}},
+		},
+	}
+
+	payload := api.Config{
+		Values: map[string]interface{}{"PORT": "5000", "OLD": nil, "NEW": "y"},
+		Memory: map[string]interface{}{"web": "512M"},
+		Healthcheck: map[string]*api.Healthchecks{
+			"web": {"livenessProbe": &api.Healthcheck{InitialDelaySeconds: 10}},
+		},
+	}
+
+	changes, err := payloadChanges(payload, current)
+	assert.NoErr(t, err)
+
+	var lines []string
+	for _, change := range changes {
+		lines = append(lines, change.String())
+	}
+
+	assert.Equal(t, lines, []string{
+		`~ healthcheck web livenessProbe={"failureThreshold":0,"initialDelaySeconds":10,"periodSeconds":0,"successThreshold":0,"timeoutSeconds":0} (was {"failureThreshold":0,"initialDelaySeconds":5,"periodSeconds":0,"successThreshold":0,"timeoutSeconds":0})`,
+		"+ values NEW=y",
+		"- values OLD (was x)",
+		"~ values PORT=5000 (was 4000)",
+	}, "changes")
+}
+
+func TestConfigSetDryRun(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	server.Mux.HandleFunc("/v2/apps/foo/config/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		if r.Method != "GET" {
+			t.Errorf("unexpected %s request", r.Method)
+		}
+		fmt.Fprintf(w, `{"app": "foo", "values": {"PORT": "4000", "DB_PASSWORD": "hunter2"}}`)
+	})
+
+	var b bytes.Buffer
+	cmdr := DeisCmd{WOut: &b, ConfigFile: cf, DryRun: true}
+
+	err = cmdr.ConfigSet("foo", []string{"PORT=5000", "MODE=test"}, false, 0)
+	assert.NoErr(t, err)
+
+	assert.Equal(t, b.String(), `=== foo Dry Run: update config
+Payload:
+{
+  "values": {
+    "MODE": "test",
+    "PORT": "5000"
+  }
+}
+
+Changes:
++ values MODE=test
+~ values PORT=5000 (was 4000)
+
+Nothing was sent to the controller, as --dry-run was given.
+`, "output")
+
+	b.Reset()
+	cmdr.Output = OutputJSON
+	err = cmdr.ConfigUnset("foo", []string{"PORT"}, false)
+	assert.NoErr(t, err)
+
+	assert.Equal(t, b.String(), `{
+  "app": "foo",
+  "action": "update config",
+  "payload": {
+    "values": {
+      "PORT": null
+    }
+  },
+  "changes": [
+    "- values PORT (was 4000)"
+  ]
+}
+`, "output")
+
+	// Secrets are masked in the payload and the changes, unless they're revealed.
+	b.Reset()
+	cmdr.Output = ""
+	err = cmdr.ConfigSet("foo", []string{"DB_PASSWORD=swordfish"}, false, 0)
+	assert.NoErr(t, err)
+
+	assert.Equal(t, b.String(), `=== foo Dry Run: update config
+Payload:
+{
+  "values": {
+    "DB_PASSWORD": "***"
+  }
+}
+
+Changes:
+~ values DB_PASSWORD=*** (was ***)
+
+Nothing was sent to the controller, as --dry-run was given.
+`, "output")
+
+	b.Reset()
+	cmdr.Output = OutputJSON
+	err = cmdr.ConfigUnset("foo", []string{"DB_PASSWORD"}, true)
+	assert.NoErr(t, err)
+
+	assert.Equal(t, b.String(), `{
+  "app": "foo",
+  "action": "update config",
+  "payload": {
+    "values": {
+      "DB_PASSWORD": null
+    }
+  },
+  "changes": [
+    "- values DB_PASSWORD (was hunter2)"
+  ]
+}
+`, "output")
+}
+
+func TestMaintenanceEnableDryRun(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	server.Mux.HandleFunc("/v2/apps/foo/settings/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		if r.Method != "GET" {
+			t.Errorf("unexpected %s request", r.Method)
+		}
+		fmt.Fprintf(w, `{"app": "foo", "maintenance": false, "routable": true}`)
+	})
+
+	var b bytes.Buffer
+	cmdr := DeisCmd{WOut: &b, ConfigFile: cf, DryRun: true}
+
+	err = cmdr.MaintenanceEnable("foo")
+	assert.NoErr(t, err)
+
+	assert.Equal(t, b.String(), `=== foo Dry Run: update settings
+Payload:
+{
+  "maintenance": true
+}
+
+Changes:
+~ maintenance=true (was false)
+
+Nothing was sent to the controller, as --dry-run was given.
+`, "output")
+}
+
+func TestPsScaleDryRun(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	// The clock type has no pods, as it is scaled to 0.
+	server.Mux.HandleFunc("/v2/apps/foo/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"id": "foo", "structure": {"web": 1, "worker": 1, "clock": 0}}`)
+	})
+
+	server.Mux.HandleFunc("/v2/apps/foo/pods/", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request for the pods, the structure is what the app is scaled to")
+	})
+
+	server.Mux.HandleFunc("/v2/apps/foo/scale/", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected %s request", r.Method)
+	})
+
+	var b bytes.Buffer
+	cmdr := DeisCmd{WOut: &b, ConfigFile: cf, DryRun: true}
+
+	err = cmdr.PsScale("foo", []string{"web=3", "worker=1", "clock=1"}, 0)
+	assert.NoErr(t, err)
+
+	assert.Equal(t, b.String(), `=== foo Dry Run: scale processes
+Payload:
+{
+  "clock": 1,
+  "web": 3,
+  "worker": 1
+}
+
+Changes:
+~ clock=1 (was 0)
+~ web=3 (was 1)
+
+Nothing was sent to the controller, as --dry-run was given.
+`, "output")
+}
+
+func TestDomainsRemoveDryRun(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	var b bytes.Buffer
+	cmdr := DeisCmd{WOut: &b, ConfigFile: cf, DryRun: true}
+
+	err = cmdr.DomainsRemove("foo", "example.com")
+	assert.NoErr(t, err)
+
+	assert.Equal(t, b.String(), `=== foo Dry Run: remove domain
+Changes:
+- domains example.com
+
+Nothing was sent to the controller, as --dry-run was given.
+`, "output")
+}
@@ -0,0 +1,301 @@
+package cmd
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deis/controller-sdk-go"
+	"github.com/deis/workflow-cli/pkg/websocket"
+	"github.com/deis/workflow-cli/settings"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// Protocols for streaming the input and output of a command, as spoken by the Kubernetes
+// exec and attach endpoints that the controller proxies. Every message starts with the
+// channel it belongs to. v5 adds a channel to close stdin, so commands reading it can finish.
+const (
+	streamProtocolV5 = "v5.channel.k8s.io"
+	streamProtocolV4 = "v4.channel.k8s.io"
+)
+
+// Channels of the stream protocols.
+const (
+	stdinChannel  byte = 0
+	stdoutChannel byte = 1
+	stderrChannel byte = 2
+	errorChannel  byte = 3
+	resizeChannel byte = 4
+	closeChannel  byte = 255
+)
+
+// streamStatus is the status sent on the error channel once the command exits.
+type streamStatus struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Reason  string `json:"reason"`
+	Details struct {
+		Causes []struct {
+			Reason  string `json:"reason"`
+			Message string `json:"message"`
+		} `json:"causes"`
+	} `json:"details"`
+}
+
+// terminalSize is sent on the resize channel whenever the local terminal changes size.
+type terminalSize struct {
+	Width  int `json:"Width"`
+	Height int `json:"Height"`
+}
+
+// PsExec runs a command in a running process, streaming its input and output. If tty is true,
+// the command gets a terminal, and the local one is put in raw mode while it runs.
+func (d *DeisCmd) PsExec(appID, name string, command []string, tty bool) error {
+	s, appID, err := load(d.ConfigFile, appID)
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{"command": command}
+	err = d.streamCommand(s, fmt.Sprintf("/v2/apps/%s/pods/%s/exec", appID, name), query,
+		d.WIn, tty, 0)
+	if err == deis.ErrNotFound {
+		return fmt.Errorf("Could not find process %s in app %s", name, appID)
+	}
+
+	return err
+}
+
+// streamCommand connects to a controller endpoint that runs a command, then copies in to it
+// and its output back until it exits. A non-zero exit code is returned as an ExitError. The
+// connection is closed, which cancels the command, if timeout isn't zero and passes or if the
+// command is interrupted with Ctrl-C.
+func (d *DeisCmd) streamCommand(s *settings.Settings, path string, query url.Values, in io.Reader,
+	tty bool, timeout time.Duration) error {
+	// The stream doesn't go through the client's transport, which refuses changes on --dry-run.
+	if d.DryRun {
+		return settings.ErrReadOnly
+	}
+
+	c := s.Client
+
+	query.Set("stdin", strconv.FormatBool(in != nil))
+	query.Set("stdout", "true")
+	// A terminal merges stderr into stdout.
+	query.Set("stderr", strconv.FormatBool(!tty))
+	query.Set("tty", strconv.FormatBool(tty))
+
+	u := *c.ControllerURL
+	u.Path = strings.TrimRight(u.Path, "/") + path
+	u.RawQuery = query.Encode()
+
+	header := http.Header{}
+	header.Set("User-Agent", c.UserAgent)
+	if c.Token != "" {
+		header.Set("Authorization", "token "+c.Token)
+	}
+
+	var tlsConfig *tls.Config
+	if transport, ok := c.HTTPClient.Transport.(*http.Transport); ok {
+		tlsConfig = transport.TLSClientConfig
+	}
+
+	conn, res, err := websocket.Dial(&u, header, tlsConfig, streamProtocolV5, streamProtocolV4)
+	if err == websocket.ErrBadHandshake {
+		return d.checkAPICompatibility(s, handshakeError(res))
+	} else if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if tty {
+		if isTerminal(in) {
+			fd := int(in.(*os.File).Fd())
+
+			state, err := terminal.MakeRaw(fd)
+			if err != nil {
+				return err
+			}
+			defer terminal.Restore(fd, state)
+
+			stop := watchTerminalSize(fd, func(size terminalSize) {
+				writeResize(conn, size)
+			})
+			defer close(stop)
+		}
+	}
+
+	if in != nil {
+		closeStdin := res.Header.Get("Sec-WebSocket-Protocol") == streamProtocolV5
+		go copyStdin(conn, in, closeStdin)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- d.copyOutput(conn)
+	}()
+
+	return d.waitCancellable(done, timeout, func() bool {
+		conn.Close()
+		// Wait for the output to stop, so it isn't printed after the command returns.
+		<-done
+		return true
+	})
+}
+
+// waitCancellable waits for a command to send its result on done. If timeout isn't zero and
+// passes first, or if the user presses Ctrl-C, it calls cancel and gives up. cancel returns
+// whether it stopped the command, or if it may still be running. A terminal in raw mode sends
+// Ctrl-C to the command instead, which handles it like it would locally.
+func (d *DeisCmd) waitCancellable(done <-chan error, timeout time.Duration, cancel func() bool) error {
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	outcome := func() string {
+		if cancel() {
+			return "the command was cancelled"
+		}
+		return "the command may still be running"
+	}
+
+	select {
+	case err := <-done:
+		return err
+	case <-interrupt:
+		d.PrintErrf("Interrupted, %s.\n", outcome())
+		// The exit code of a shell for a command interrupted with SIGINT.
+		return ExitError{Code: 130}
+	case <-expired:
+		return fmt.Errorf("timed out after %s, %s", timeout, outcome())
+	}
+}
+
+// copyStdin sends input on the stdin channel until it ends, then closes the channel if the
+// protocol supports it.
+func copyStdin(conn *websocket.Conn, in io.Reader, closeStdin bool) {
+	buf := make([]byte, 32*1024)
+
+	for {
+		n, err := in.Read(buf)
+		if n > 0 {
+			message := append([]byte{stdinChannel}, buf[:n]...)
+			if conn.WriteMessage(websocket.BinaryMessage, message) != nil {
+				return
+			}
+		}
+
+		if err != nil {
+			if closeStdin {
+				conn.WriteMessage(websocket.BinaryMessage, []byte{closeChannel, stdinChannel})
+			}
+			return
+		}
+	}
+}
+
+// copyOutput writes the stdout and stderr channels to the command's writers until the exit
+// status arrives on the error channel.
+func (d *DeisCmd) copyOutput(conn *websocket.Conn) error {
+	for {
+		_, message, err := conn.ReadMessage()
+		if err == websocket.ErrCloseReceived {
+			// The server closed the stream without a status, which the protocol allows when the
+			// command succeeded.
+			return nil
+		} else if err == websocket.ErrConnectionLost {
+			return errors.New("the connection to the controller was lost, the command may not have finished")
+		} else if err != nil {
+			return err
+		}
+
+		if len(message) == 0 {
+			continue
+		}
+
+		switch message[0] {
+		case stdoutChannel:
+			d.Print(string(message[1:]))
+		case stderrChannel:
+			d.PrintErr(string(message[1:]))
+		case errorChannel:
+			return statusError(message[1:])
+		}
+	}
+}
+
+func writeResize(conn *websocket.Conn, size terminalSize) {
+	body, err := json.Marshal(size)
+	if err != nil {
+		return
+	}
+
+	conn.WriteMessage(websocket.BinaryMessage, append([]byte{resizeChannel}, body...))
+}
+
+// statusError turns the exit status of a command into an error, an ExitError if the command
+// exited with a non-zero code.
+func statusError(body []byte) error {
+	var status streamStatus
+	if err := json.Unmarshal(body, &status); err != nil {
+		return fmt.Errorf("invalid exit status %q: %v", body, err)
+	}
+
+	if status.Status == "Success" {
+		return nil
+	}
+
+	if status.Reason == "NonZeroExitCode" {
+		for _, cause := range status.Details.Causes {
+			if cause.Reason == "ExitCode" {
+				if code, err := strconv.Atoi(cause.Message); err == nil {
+					return ExitError{Code: code}
+				}
+			}
+		}
+	}
+
+	if status.Message == "" {
+		return errors.New("command failed")
+	}
+
+	return errors.New(status.Message)
+}
+
+// handshakeError turns a refused connection into the error the SDK returns for its status.
+func handshakeError(res *http.Response) error {
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusUnauthorized:
+		return deis.ErrUnauthorized
+	case http.StatusForbidden:
+		return deis.ErrForbidden
+	case http.StatusNotFound:
+		return deis.ErrNotFound
+	case http.StatusMethodNotAllowed:
+		return deis.ErrNotAllowed
+	case http.StatusConflict:
+		return deis.ErrConflict
+	}
+
+	body, _ := ioutil.ReadAll(res.Body)
+	return fmt.Errorf("Unknown Error (%d): %s", res.StatusCode, body)
+}
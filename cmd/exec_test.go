@@ -0,0 +1,253 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arschles/assert"
+	"github.com/deis/controller-sdk-go/api"
+	"github.com/deis/workflow-cli/pkg/testutil"
+	"github.com/deis/workflow-cli/pkg/websocket"
+	"github.com/deis/workflow-cli/settings"
+)
+
+// serveExec stands in for an exec endpoint running cat: it echoes stdin to stdout until stdin
+// is closed, writes a warning to stderr, then exits with code 3.
+func serveExec(t *testing.T, w http.ResponseWriter, r *http.Request) {
+	conn, err := websocket.Upgrade(w, r, streamProtocolV5)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer conn.Close()
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if message[0] == closeChannel {
+			break
+		} else if message[0] == stdinChannel {
+			conn.WriteMessage(websocket.BinaryMessage, append([]byte{stdoutChannel}, message[1:]...))
+		}
+	}
+
+	conn.WriteMessage(websocket.BinaryMessage, append([]byte{stderrChannel}, "warning\n"...))
+	conn.WriteMessage(websocket.BinaryMessage, append([]byte{errorChannel},
+		`{"status":"Failure","reason":"NonZeroExitCode","message":"command terminated with non-zero exit code",
+		"details":{"causes":[{"reason":"ExitCode","message":"3"}]}}`...))
+}
+
+func TestPsExec(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	server.Mux.HandleFunc("/v2/apps/foo/pods/foo-web-4084101150-c871y/exec", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		assert.Equal(t, query["command"], []string{"cat", "-"}, "command")
+		assert.Equal(t, query.Get("stdin"), "true", "stdin")
+		assert.Equal(t, query.Get("stderr"), "true", "stderr")
+		assert.Equal(t, query.Get("tty"), "false", "tty")
+
+		serveExec(t, w, r)
+	})
+
+	var b, e bytes.Buffer
+	cmdr := DeisCmd{WOut: &b, WErr: &e, WIn: strings.NewReader("hello\n"), ConfigFile: cf}
+
+	err = cmdr.PsExec("foo", "foo-web-4084101150-c871y", []string{"cat", "-"}, false)
+	assert.Err(t, ExitError{Code: 3}, err)
+	assert.Equal(t, b.String(), "hello\n", "output")
+	assert.Equal(t, e.String(), "warning\n", "errors")
+
+	err = cmdr.PsExec("foo", "foo-web-ghost", []string{"ls"}, false)
+	assert.Err(t, errors.New("Could not find process foo-web-ghost in app foo"), err)
+
+	// Commands aren't run at all on --dry-run.
+	cmdr.DryRun = true
+	err = cmdr.PsExec("foo", "foo-web-4084101150-c871y", []string{"rm", "-rf", "/app"}, false)
+	assert.Err(t, settings.ErrReadOnly, err)
+}
+
+func TestAppRunTTY(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	server.Mux.HandleFunc("/v2/apps/foo/run", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.URL.Query().Get("command"), "bash", "command")
+		assert.Equal(t, r.URL.Query().Get("tty"), "true", "tty")
+
+		conn, err := websocket.Upgrade(w, r, streamProtocolV4)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		conn.WriteMessage(websocket.BinaryMessage, append([]byte{stdoutChannel}, message[1:]...))
+		conn.WriteMessage(websocket.BinaryMessage, append([]byte{errorChannel}, `{"status":"Success"}`...))
+	})
+
+	var b bytes.Buffer
+	cmdr := DeisCmd{WOut: &b, WIn: strings.NewReader("exit\r"), ConfigFile: cf}
+
+	err = cmdr.AppRun("foo", "bash", true, 0)
+	assert.NoErr(t, err)
+	assert.Equal(t, b.String(), "exit\r", "output")
+}
+
+func TestAppRun(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	server.Mux.HandleFunc("/v2/apps/foo/run", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.URL.Query().Get("stdin"), "false", "stdin")
+		assert.Equal(t, r.URL.Query().Get("tty"), "false", "tty")
+
+		conn, err := websocket.Upgrade(w, r, streamProtocolV5)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+
+		for _, line := range []string{"Migrating...\n", "Migrated 3 tables\n"} {
+			conn.WriteMessage(websocket.BinaryMessage, append([]byte{stdoutChannel}, line...))
+		}
+		conn.WriteMessage(websocket.BinaryMessage, append([]byte{errorChannel},
+			`{"status":"Failure","reason":"NonZeroExitCode","details":{"causes":[{"reason":"ExitCode","message":"2"}]}}`...))
+	})
+
+	var b bytes.Buffer
+	cmdr := DeisCmd{WOut: &b, WIn: strings.NewReader("ignored"), ConfigFile: cf}
+
+	err = cmdr.AppRun("foo", "rake db:migrate", false, 0)
+	assert.Err(t, ExitError{Code: 2}, err)
+	assert.Equal(t, b.String(), "Running 'rake db:migrate'...\nMigrating...\nMigrated 3 tables\n", "output")
+}
+
+func TestAppRunConnectionLost(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	server.Mux.HandleFunc("/v2/apps/foo/run", func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Upgrade(w, r, streamProtocolV5)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		conn.WriteMessage(websocket.BinaryMessage, append([]byte{stdoutChannel}, "Migrating...\n"...))
+		// The pod is killed, so the connection drops without a status or a close message.
+		conn.Abort()
+	})
+
+	var b bytes.Buffer
+	cmdr := DeisCmd{WOut: &b, ConfigFile: cf}
+
+	err = cmdr.AppRun("foo", "rake db:migrate", false, 0)
+	assert.Err(t, errors.New("the connection to the controller was lost, the command may not have finished"), err)
+	assert.Equal(t, b.String(), "Running 'rake db:migrate'...\nMigrating...\n", "output")
+}
+
+func TestAppRunTimeout(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	cancelled := make(chan bool, 1)
+	server.Mux.HandleFunc("/v2/apps/foo/run", func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Upgrade(w, r, streamProtocolV5)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+
+		conn.WriteMessage(websocket.BinaryMessage, append([]byte{stdoutChannel}, "sleeping\n"...))
+
+		// The command never exits, so the client has to close the connection.
+		_, _, err = conn.ReadMessage()
+		cancelled <- err != nil
+	})
+
+	var b bytes.Buffer
+	cmdr := DeisCmd{WOut: &b, ConfigFile: cf}
+
+	err = cmdr.AppRun("foo", "sleep 3600", false, 50*time.Millisecond)
+	assert.Err(t, errors.New("timed out after 50ms, the command was cancelled"), err)
+	assert.Equal(t, b.String(), "Running 'sleep 3600'...\nsleeping\n", "output")
+	assert.Equal(t, <-cancelled, true, "cancelled")
+}
+
+func TestAppRunToCompletion(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	// A controller without the streaming endpoint only accepts POST.
+	server.Mux.HandleFunc("/v2/apps/foo/run", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		testutil.SetHeaders(w)
+		testutil.AssertBody(t, api.AppRunRequest{Command: "ls /missing"}, r)
+		fmt.Fprint(w, `{"exit_code": 1, "output": "ls: /missing: No such file or directory\n"}`)
+	})
+
+	var b, e bytes.Buffer
+	cmdr := DeisCmd{WOut: &b, WErr: &e, ConfigFile: cf}
+
+	err = cmdr.AppRun("foo", "ls /missing", false, time.Minute)
+	assert.Err(t, ExitError{Code: 1}, err)
+	assert.Equal(t, b.String(), "Running 'ls /missing'...\n", "output")
+	assert.Equal(t, e.String(), "ls: /missing: No such file or directory\n", "errors")
+}
+
+func TestStatusError(t *testing.T) {
+	t.Parallel()
+
+	assert.NoErr(t, statusError([]byte(`{"status":"Success"}`)))
+	assert.Err(t, ExitError{Code: 127}, statusError([]byte(
+		`{"status":"Failure","reason":"NonZeroExitCode","details":{"causes":[{"reason":"ExitCode","message":"127"}]}}`)))
+	assert.Err(t, errors.New("container not found"), statusError([]byte(
+		`{"status":"Failure","message":"container not found"}`)))
+}
@@ -0,0 +1,37 @@
+// +build linux darwin
+
+package cmd
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// watchTerminalSize calls resize with the size of a terminal, then again whenever it changes,
+// until the returned channel is closed.
+func watchTerminalSize(fd int, resize func(terminalSize)) chan<- struct{} {
+	stop := make(chan struct{})
+	changed := make(chan os.Signal, 1)
+	signal.Notify(changed, syscall.SIGWINCH)
+
+	go func() {
+		defer signal.Stop(changed)
+
+		for {
+			if width, height, err := terminal.GetSize(fd); err == nil {
+				resize(terminalSize{Width: width, Height: height})
+			}
+
+			select {
+			case <-changed:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	return stop
+}
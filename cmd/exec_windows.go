@@ -0,0 +1,17 @@
+// +build windows
+
+package cmd
+
+import (
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// watchTerminalSize calls resize with the size of a terminal. Windows doesn't signal when a
+// console changes size, so it isn't called again.
+func watchTerminalSize(fd int, resize func(terminalSize)) chan<- struct{} {
+	if width, height, err := terminal.GetSize(fd); err == nil {
+		resize(terminalSize{Width: width, Height: height})
+	}
+
+	return make(chan struct{})
+}
@@ -36,6 +36,10 @@ func (d *DeisCmd) HealthchecksList(appID, procType string) error {
 		return err
 	}
 
+	if d.structured() {
+		return d.printStructured(api.Config{Healthcheck: config.Healthcheck})
+	}
+
 	d.Printf("=== %s Healthchecks\n", appID)
 	if procType == "" {
 		if len(config.Healthcheck) == 0 {
@@ -71,15 +75,19 @@ func (d *DeisCmd) HealthchecksSet(appID, healthcheckType, procType string, probe
 		return err
 	}
 
-	d.Printf("Applying %s healthcheck... ", healthcheckType)
-
-	quit := progress(d.WOut)
-
 	healthcheckMap := make(api.Healthchecks)
 	healthcheckMap[healthcheckType] = probe
 	configObj := api.Config{Healthcheck: make(map[string]*api.Healthchecks)}
 	configObj.Healthcheck[procType] = &healthcheckMap
 
+	if d.DryRun {
+		return d.dryRunConfig(s, appID, configObj, false)
+	}
+
+	d.Printf("Applying %s healthcheck... ", healthcheckType)
+
+	quit := progress(d.WOut)
+
 	_, err = config.Set(s.Client, appID, configObj)
 
 	quit <- true
@@ -101,10 +109,6 @@ func (d *DeisCmd) HealthchecksUnset(appID, procType string, healthchecks []strin
 		return err
 	}
 
-	d.Print("Removing healthchecks... ")
-
-	quit := progress(d.WOut)
-
 	configObj := api.Config{}
 
 	healthchecksMap := make(map[string]*api.Healthchecks)
@@ -117,6 +121,14 @@ func (d *DeisCmd) HealthchecksUnset(appID, procType string, healthchecks []strin
 
 	configObj.Healthcheck = healthchecksMap
 
+	if d.DryRun {
+		return d.dryRunConfig(s, appID, configObj, false)
+	}
+
+	d.Print("Removing healthchecks... ")
+
+	quit := progress(d.WOut)
+
 	_, err = config.Set(s.Client, appID, configObj)
 
 	quit <- true
@@ -29,10 +29,14 @@ func (d *DeisCmd) KeysList(results int) error {
 	}
 
 	keys, count, err := keys.List(s.Client, results)
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
+	if d.structured() {
+		return d.printStructured(keys)
+	}
+
 	d.Printf("=== %s Keys%s", s.Username, limitCount(len(keys), count))
 
 	w := tabwriter.NewWriter(d.WOut, 0, 8, 1, ' ', 0)
@@ -54,7 +58,7 @@ func (d *DeisCmd) KeyRemove(keyID string) error {
 
 	d.Printf("Removing %s SSH Key...", keyID)
 
-	if err = keys.Delete(s.Client, keyID); d.checkAPICompatibility(s.Client, err) != nil {
+	if err = keys.Delete(s.Client, keyID); d.checkAPICompatibility(s, err) != nil {
 		d.Println()
 		return err
 	}
@@ -106,7 +110,7 @@ func (d *DeisCmd) KeyAdd(name string, keyLocation string) error {
 
 	d.Printf("Uploading %s to deis...", filepath.Base(key.Name))
 
-	if _, err = keys.New(s.Client, key.ID, key.Public); d.checkAPICompatibility(s.Client, err) != nil {
+	if _, err = keys.New(s.Client, key.ID, key.Public); d.checkAPICompatibility(s, err) != nil {
 		d.Println()
 		return err
 	}
@@ -19,10 +19,14 @@ func (d *DeisCmd) LabelsList(appID string) error {
 	}
 
 	appSettings, err := appsettings.List(s.Client, appID)
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
+	if d.structured() {
+		return d.printStructured(api.AppSettings{Label: appSettings.Label})
+	}
+
 	sortedLabels := sortKeys(appSettings.Label)
 
 	d.Printf("=== %s Label\n", appID)
@@ -56,6 +60,10 @@ func (d *DeisCmd) LabelsSet(appID string, labels []string) error {
 		return err
 	}
 
+	if d.DryRun {
+		return d.dryRunAppSettings(s, appID, api.AppSettings{Label: labelsMap})
+	}
+
 	d.Printf("Applying labels on %s... ", appID)
 
 	quit := progress(d.WOut)
@@ -87,6 +95,10 @@ func (d *DeisCmd) LabelsUnset(appID string, labels []string) error {
 		labelsMap[label] = nil
 	}
 
+	if d.DryRun {
+		return d.dryRunAppSettings(s, appID, api.AppSettings{Label: labelsMap})
+	}
+
 	d.Printf("Removing labels on %s... ", appID)
 
 	quit := progress(d.WOut)
@@ -19,10 +19,14 @@ func (d *DeisCmd) LimitsList(appID string) error {
 	}
 
 	config, err := config.List(s.Client, appID)
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
+	if d.structured() {
+		return d.printStructured(api.Config{Memory: config.Memory, CPU: config.CPU})
+	}
+
 	d.Printf("=== %s Limits\n\n", appID)
 
 	d.Println("--- Memory")
@@ -67,9 +71,6 @@ func (d *DeisCmd) LimitsSet(appID string, limits []string, limitType string) err
 		return err
 	}
 
-	d.Print("Applying limits... ")
-
-	quit := progress(d.WOut)
 	configObj := api.Config{}
 
 	if limitType == "cpu" {
@@ -78,10 +79,17 @@ func (d *DeisCmd) LimitsSet(appID string, limits []string, limitType string) err
 		configObj.Memory = limitsMap
 	}
 
+	if d.DryRun {
+		return d.dryRunConfig(s, appID, configObj, false)
+	}
+
+	d.Print("Applying limits... ")
+
+	quit := progress(d.WOut)
 	_, err = config.Set(s.Client, appID, configObj)
 	quit <- true
 	<-quit
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
@@ -98,10 +106,6 @@ func (d *DeisCmd) LimitsUnset(appID string, limits []string, limitType string) e
 		return err
 	}
 
-	d.Print("Applying limits... ")
-
-	quit := progress(d.WOut)
-
 	configObj := api.Config{}
 
 	valuesMap := make(map[string]interface{})
@@ -116,10 +120,18 @@ func (d *DeisCmd) LimitsUnset(appID string, limits []string, limitType string) e
 		configObj.Memory = valuesMap
 	}
 
+	if d.DryRun {
+		return d.dryRunConfig(s, appID, configObj, false)
+	}
+
+	d.Print("Applying limits... ")
+
+	quit := progress(d.WOut)
+
 	_, err = config.Set(s.Client, appID, configObj)
 	quit <- true
 	<-quit
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deis/controller-sdk-go/apps"
+	"github.com/deis/workflow-cli/pkg/logging"
+	"github.com/deis/workflow-cli/settings"
+)
+
+// controllerPod is the pod name the controller's messages are logged under.
+const controllerPod = "deis-controller"
+
+var (
+	// logLineRegex matches log lines such as "2017-03-10T16:33:09UTC foo[foo-web-2175-9xk2p]: hi".
+	logLineRegex = regexp.MustCompile(`^(\S+) (\S+)\[([^\]]+)\]: ?(.*)$`)
+	// podHashRegex matches the replica set hash in a pod name.
+	podHashRegex = regexp.MustCompile(`^[0-9a-z]*[0-9][0-9a-z]*$`)
+)
+
+// logReconnectDelay is how long to wait before reconnecting to a dropped log stream. It
+// doubles after each failed attempt, up to logReconnectMaxDelay, and the stream is given up
+// after logReconnectAttempts attempts in a row.
+var (
+	logReconnectDelay    = time.Second
+	logReconnectMaxDelay = 30 * time.Second
+	logReconnectAttempts = 10
+)
+
+// logRecord is a parsed log line.
+type logRecord struct {
+	Timestamp string `json:"timestamp,omitempty"`
+	Source    string `json:"source,omitempty"`
+	Process   string `json:"process,omitempty"`
+	Pod       string `json:"pod,omitempty"`
+	Message   string `json:"message"`
+
+	time time.Time
+	line string
+}
+
+// logFilter selects the log records to print. Empty fields match everything.
+type logFilter struct {
+	processes []string
+	grep      *regexp.Regexp
+	since     time.Time
+	until     time.Time
+}
+
+// processType returns the process type of a pod, such as "web" for "foo-web-3869063683-a5wrx".
+func processType(appID, pod string) string {
+	parts := strings.Split(strings.TrimPrefix(pod, appID+"-"), "-")
+
+	if len(parts) > 1 {
+		parts = parts[:len(parts)-1]
+	}
+	if len(parts) > 1 && podHashRegex.MatchString(parts[len(parts)-1]) {
+		parts = parts[:len(parts)-1]
+	}
+
+	return strings.Join(parts, "-")
+}
+
+// parseLogLine parses a log line. Lines that aren't in the format of the logger are kept as
+// the message of a record without any other fields.
+func parseLogLine(appID, line string) logRecord {
+	record := logRecord{Message: line, line: line}
+
+	captures := logLineRegex.FindStringSubmatch(line)
+	if captures == nil {
+		return record
+	}
+
+	record.Timestamp = captures[1]
+	record.Message = captures[4]
+
+	record.time, _ = parseTimestamp(captures[1])
+
+	if captures[3] == controllerPod {
+		record.Source = "controller"
+	} else {
+		record.Source = "app"
+		record.Pod = captures[3]
+		record.Process = processType(appID, captures[3])
+	}
+
+	return record
+}
+
+// splitLogs splits the logs returned by the controller, which escapes their newlines, into
+// lines.
+func splitLogs(logs string) []string {
+	if unquoted, err := strconv.Unquote(`"` + logs + `"`); err == nil {
+		logs = unquoted
+	}
+
+	return strings.Split(strings.TrimRight(logs, "\n"), "\n")
+}
+
+// matches returns true if a record is selected by the filter. Records without a timestamp
+// or process type only match filters that don't need them.
+func (f logFilter) matches(record logRecord) bool {
+	if len(f.processes) > 0 {
+		found := false
+		for _, process := range f.processes {
+			if record.Process == process {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if !f.since.IsZero() && (record.time.IsZero() || record.time.Before(f.since)) {
+		return false
+	}
+
+	if !f.until.IsZero() && (record.time.IsZero() || record.time.After(f.until)) {
+		return false
+	}
+
+	return f.grep == nil || f.grep.MatchString(record.line)
+}
+
+// printLogRecord prints a record as JSON, or as the original line colored by its category.
+func (d *DeisCmd) printLogRecord(record logRecord, jsonOutput bool) error {
+	if !jsonOutput {
+		logging.PrintLog(d.WOut, record.line)
+		return nil
+	}
+
+	out, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	d.Println(string(out))
+	return nil
+}
+
+// errLogsDone stops reading a log stream once records are past the filter's time range.
+var errLogsDone = errors.New("past the end of the time range")
+
+// tailLogs streams an app's logs, reconnecting with a growing delay when the stream drops.
+// It returns once a record is past the end of the filter's time range.
+func (d *DeisCmd) tailLogs(s *settings.Settings, appID, process string, filter logFilter, jsonOutput bool) error {
+	delay := logReconnectDelay
+	failures := 0
+	connected := false
+
+	for {
+		res, err := apps.LogsTail(s.Client, appID, process)
+		if err = d.checkAPICompatibility(s, err); err != nil && !connected {
+			// Failing to connect in the first place is reported like any other request.
+			return err
+		}
+
+		if err == nil {
+			connected = true
+
+			var received bool
+			received, err = d.readLogStream(res.Body, appID, filter, jsonOutput)
+			res.Body.Close()
+
+			if err == errLogsDone {
+				return nil
+			}
+			if received {
+				delay = logReconnectDelay
+				failures = 0
+			}
+		}
+
+		failures++
+		if failures > logReconnectAttempts {
+			return err
+		}
+
+		d.PrintErrf("Log stream disconnected (%v), reconnecting in %s...\n", err, delay)
+		time.Sleep(delay)
+
+		if delay *= 2; delay > logReconnectMaxDelay {
+			delay = logReconnectMaxDelay
+		}
+	}
+}
+
+// readLogStream prints the records of a log stream until it ends, and returns whether any
+// lines were received.
+func (d *DeisCmd) readLogStream(body io.Reader, appID string, filter logFilter, jsonOutput bool) (bool, error) {
+	reader := bufio.NewReader(body)
+	received := false
+
+	for {
+		line, err := reader.ReadString('\n')
+		if line = strings.TrimRight(line, "\r\n"); line != "" {
+			received = true
+			record := parseLogLine(appID, line)
+
+			if !filter.until.IsZero() && record.time.After(filter.until) {
+				return received, errLogsDone
+			}
+
+			if filter.matches(record) {
+				if printErr := d.printLogRecord(record, jsonOutput); printErr != nil {
+					return received, printErr
+				}
+			}
+		}
+
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return received, err
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arschles/assert"
+	"github.com/deis/workflow-cli/pkg/testutil"
+)
+
+func TestProcessType(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"foo-web-3869063683-a5wrx":        "web",
+		"foo-worker-74d9c5b9f6-x2x8q":     "worker",
+		"foo-background-job-412312-kq8wz": "background-job",
+		"foo-run-kq8wz":                   "run",
+		"web-3869063683-a5wrx":            "web",
+	}
+
+	for pod, expected := range cases {
+		assert.Equal(t, processType("foo", pod), expected, pod)
+	}
+}
+
+func TestParseLogLine(t *testing.T) {
+	t.Parallel()
+
+	record := parseLogLine("foo", "2017-03-10T16:33:09UTC foo[foo-web-3869063683-a5wrx]: Listening on 5000")
+	assert.Equal(t, record.Timestamp, "2017-03-10T16:33:09UTC", "timestamp")
+	assert.Equal(t, record.Source, "app", "source")
+	assert.Equal(t, record.Process, "web", "process")
+	assert.Equal(t, record.Pod, "foo-web-3869063683-a5wrx", "pod")
+	assert.Equal(t, record.Message, "Listening on 5000", "message")
+	assert.Equal(t, record.time.Equal(time.Date(2017, 3, 10, 16, 33, 9, 0, time.UTC)), true, "time")
+
+	record = parseLogLine("foo", "2017-03-10T16:33:09+00:00 foo[deis-controller]: admin scaled pods web=2")
+	assert.Equal(t, record.Source, "controller", "source")
+	assert.Equal(t, record.Process, "", "process")
+	assert.Equal(t, record.Message, "admin scaled pods web=2", "message")
+
+	record = parseLogLine("foo", "INFO [foo]: something happened")
+	assert.Equal(t, record, logRecord{Message: "INFO [foo]: something happened", line: "INFO [foo]: something happened"}, "record")
+}
+
+func TestSplitLogs(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, splitLogs(`one\ntwo \"quoted\"\n`), []string{"one", `two "quoted"`}, "lines")
+	assert.Equal(t, splitLogs("one\ntwo\n"), []string{"one", "two"}, "lines")
+}
+
+func TestLogFilter(t *testing.T) {
+	t.Parallel()
+
+	web := parseLogLine("foo", "2017-03-10T16:00:00UTC foo[foo-web-3869063683-a5wrx]: GET / 200")
+	worker := parseLogLine("foo", "2017-03-10T17:00:00UTC foo[foo-worker-3869063683-b6xys]: job failed")
+	plain := parseLogLine("foo", "something else")
+
+	filter := logFilter{processes: []string{"web", "worker"}}
+	assert.Equal(t, filter.matches(web), true, "web")
+	assert.Equal(t, filter.matches(worker), true, "worker")
+	assert.Equal(t, filter.matches(plain), false, "plain")
+
+	filter = logFilter{grep: regexp.MustCompile("fail")}
+	assert.Equal(t, filter.matches(web), false, "web")
+	assert.Equal(t, filter.matches(worker), true, "worker")
+
+	filter = logFilter{since: time.Date(2017, 3, 10, 16, 30, 0, 0, time.UTC)}
+	assert.Equal(t, filter.matches(web), false, "web")
+	assert.Equal(t, filter.matches(worker), true, "worker")
+	assert.Equal(t, filter.matches(plain), false, "plain")
+
+	filter = logFilter{until: time.Date(2017, 3, 10, 16, 30, 0, 0, time.UTC)}
+	assert.Equal(t, filter.matches(web), true, "web")
+	assert.Equal(t, filter.matches(worker), false, "worker")
+}
+
+func TestAppLogs(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	server.Mux.HandleFunc("/v2/apps/foo/logs", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprint(w, `"2017-03-10T16:00:00UTC foo[foo-web-3869063683-a5wrx]: GET / 200\n`+
+			`2017-03-10T16:10:00UTC foo[foo-worker-3869063683-b6xys]: job failed\n`+
+			`2017-03-10T16:20:00UTC foo[deis-controller]: admin scaled pods web=2\n"`)
+	})
+
+	var b bytes.Buffer
+	cmdr := DeisCmd{WOut: &b, ConfigFile: cf}
+
+	err = cmdr.AppLogs("foo", -1, []string{"worker"}, false, "", time.Time{}, time.Time{}, true)
+	assert.NoErr(t, err)
+	assert.Equal(t, b.String(), `{"timestamp":"2017-03-10T16:10:00UTC","source":"app","process":"worker","pod":"foo-worker-3869063683-b6xys","message":"job failed"}
+`, "output")
+
+	b.Reset()
+	err = cmdr.AppLogs("foo", -1, nil, false, "scaled|GET", time.Date(2017, 3, 10, 16, 5, 0, 0, time.UTC),
+		time.Time{}, false)
+	assert.NoErr(t, err)
+	assert.Equal(t, strings.Contains(b.String(), "admin scaled pods web=2"), true, "controller line")
+	assert.Equal(t, strings.Contains(b.String(), "GET / 200"), false, "web line")
+
+	err = cmdr.AppLogs("foo", -1, nil, false, "(", time.Time{}, time.Time{}, false)
+	assert.ExistsErr(t, err, "invalid pattern")
+}
+
+func TestAppLogsTailReconnect(t *testing.T) {
+	logReconnectDelay = time.Millisecond
+	defer func() { logReconnectDelay = time.Second }()
+
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	connections := 0
+	server.Mux.HandleFunc("/v2/apps/foo/logs", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.URL.Query().Get("tail"), "true", "tail")
+		testutil.SetHeaders(w)
+
+		connections++
+		switch connections {
+		case 1:
+			fmt.Fprintln(w, "2017-03-10T16:00:00UTC foo[foo-web-3869063683-a5wrx]: first")
+		case 2:
+			fmt.Fprintln(w, "2017-03-10T16:10:00UTC foo[foo-web-3869063683-a5wrx]: second")
+			fmt.Fprintln(w, "2017-03-10T17:00:00UTC foo[foo-web-3869063683-a5wrx]: too late")
+		}
+	})
+
+	var b, e bytes.Buffer
+	cmdr := DeisCmd{WOut: &b, WErr: &e, ConfigFile: cf}
+
+	err = cmdr.AppLogs("foo", -1, nil, true, "", time.Time{}, time.Date(2017, 3, 10, 16, 30, 0, 0, time.UTC), true)
+	assert.NoErr(t, err)
+	assert.Equal(t, connections, 2, "connections")
+	assert.Equal(t, b.String(), `{"timestamp":"2017-03-10T16:00:00UTC","source":"app","process":"web","pod":"foo-web-3869063683-a5wrx","message":"first"}
+{"timestamp":"2017-03-10T16:10:00UTC","source":"app","process":"web","pod":"foo-web-3869063683-a5wrx","message":"second"}
+`, "output")
+	assert.Equal(t, e.String(), "Log stream disconnected (unexpected EOF), reconnecting in 1ms...\n", "errors")
+}
@@ -14,10 +14,14 @@ func (d *DeisCmd) MaintenanceInfo(appID string) error {
 	}
 
 	appSettings, err := appsettings.List(s.Client, appID)
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
+	if d.structured() {
+		return d.printStructured(api.AppSettings{Maintenance: appSettings.Maintenance})
+	}
+
 	if appSettings.Maintenance == nil || !*appSettings.Maintenance {
 		d.Println("Maintenance mode is off.")
 	} else {
@@ -34,10 +38,16 @@ func (d *DeisCmd) MaintenanceEnable(appID string) error {
 		return err
 	}
 
+	b := true
+
+	if d.DryRun {
+		return d.dryRunAppSettings(s, appID, api.AppSettings{Maintenance: &b})
+	}
+
 	d.Printf("Enabling maintenance mode for %s... ", appID)
 
 	quit := progress(d.WOut)
-	b := true
+
 	_, err = appsettings.Set(s.Client, appID, api.AppSettings{Maintenance: &b})
 
 	quit <- true
@@ -59,10 +69,16 @@ func (d *DeisCmd) MaintenanceDisable(appID string) error {
 		return err
 	}
 
+	b := false
+
+	if d.DryRun {
+		return d.dryRunAppSettings(s, appID, api.AppSettings{Maintenance: &b})
+	}
+
 	d.Printf("Disabling maintenance mode for %s... ", appID)
 
 	quit := progress(d.WOut)
-	b := false
+
 	_, err = appsettings.Set(s.Client, appID, api.AppSettings{Maintenance: &b})
 
 	quit <- true
@@ -0,0 +1,1005 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+
+	"github.com/ghodss/yaml"
+	"k8s.io/api/core/v1"
+
+	"github.com/deis/controller-sdk-go/api"
+	"github.com/deis/controller-sdk-go/apps"
+	"github.com/deis/controller-sdk-go/appsettings"
+	"github.com/deis/controller-sdk-go/builds"
+	"github.com/deis/controller-sdk-go/certs"
+	"github.com/deis/controller-sdk-go/config"
+	"github.com/deis/controller-sdk-go/domains"
+	"github.com/deis/controller-sdk-go/perms"
+	"github.com/deis/controller-sdk-go/releases"
+	"github.com/deis/controller-sdk-go/tls"
+	"github.com/deis/controller-sdk-go/whitelist"
+	"github.com/deis/workflow-cli/settings"
+)
+
+// Manifest describes the desired state of an application. Sections that are left out of a
+// manifest are not managed by it, sections that are present replace the app's current state.
+type Manifest struct {
+	App           string                               `json:"app,omitempty"`
+	Config        map[string]interface{}               `json:"config,omitempty"`
+	Memory        map[string]interface{}               `json:"memory,omitempty"`
+	CPU           map[string]interface{}               `json:"cpu,omitempty"`
+	Healthchecks  map[string]*api.Healthchecks         `json:"healthchecks,omitempty"`
+	Tolerations   map[string]map[string]*v1.Toleration `json:"tolerations,omitempty"`
+	Annotations   map[string]api.Annotation            `json:"annotations,omitempty"`
+	Tags          map[string]interface{}               `json:"tags,omitempty"`
+	Labels        map[string]interface{}               `json:"labels,omitempty"`
+	Autoscale     map[string]*api.Autoscale            `json:"autoscale,omitempty"`
+	Whitelist     []string                             `json:"whitelist,omitempty"`
+	Domains       []string                             `json:"domains,omitempty"`
+	Certs         map[string][]string                  `json:"certs,omitempty"`
+	Perms         []string                             `json:"perms,omitempty"`
+	Routable      *bool                                `json:"routable,omitempty"`
+	HTTPSEnforced *bool                                `json:"https_enforced,omitempty"`
+	Maintenance   *bool                                `json:"maintenance,omitempty"`
+}
+
+// appState is the live state of an application that a manifest is compared against.
+type appState struct {
+	Config      api.Config
+	AppSettings api.AppSettings
+	Whitelist   []string
+	Domains     []string
+	Certs       map[string][]string
+	Perms       []string
+	TLS         api.TLS
+}
+
+// manifestChange is a single line of a plan.
+type manifestChange struct {
+	Op      string
+	Section string
+	Key     string
+	Old     string
+	New     string
+}
+
+func (c manifestChange) String() string {
+	target := c.Section
+	if c.Key != "" {
+		target += " " + c.Key
+	}
+
+	switch c.Op {
+	case "+":
+		if c.New == "" {
+			return fmt.Sprintf("+ %s", target)
+		}
+		return fmt.Sprintf("+ %s=%s", target, c.New)
+	case "-":
+		if c.Old == "" {
+			return fmt.Sprintf("- %s", target)
+		}
+		return fmt.Sprintf("- %s (was %s)", target, c.Old)
+	default:
+		return fmt.Sprintf("~ %s=%s (was %s)", target, c.New, c.Old)
+	}
+}
+
+// MarshalJSON renders a change as its plan line, as it reads better than its fields.
+func (c manifestChange) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.String())
+}
+
+// manifestPlan holds the changes needed to bring an app to the state of a manifest, grouped
+// by the API calls that apply them.
+type manifestPlan struct {
+	Changes         []manifestChange
+	Config          *api.Config
+	AppSettings     *api.AppSettings
+	AddWhitelist    []string
+	RemoveWhitelist []string
+	AddDomains      []string
+	RemoveDomains   []string
+	AttachCerts     []certDomain
+	DetachCerts     []certDomain
+	AddPerms        []string
+	RemovePerms     []string
+	HTTPSEnforced   *bool
+}
+
+// certDomain is a domain a certificate is attached to.
+type certDomain struct {
+	Cert   string
+	Domain string
+}
+
+func parseManifest(contents []byte) (Manifest, error) {
+	m := Manifest{}
+	if err := yaml.Unmarshal(contents, &m); err != nil {
+		return Manifest{}, fmt.Errorf("could not parse manifest: %v", err)
+	}
+
+	return m, nil
+}
+
+// manifestValue renders a scalar value the way the controller stores it.
+func manifestValue(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	default:
+		return fmt.Sprintf("%v", value)
+	}
+}
+
+func jsonValue(v interface{}) string {
+	out, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+
+	return string(out)
+}
+
+func sortedKeys(sets ...map[string]string) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, set := range sets {
+		for k := range set {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// diffFlat compares rendered maps and calls set for every key that must change. remove is
+// true when the key is missing from desired.
+func diffFlat(section string, desired, live map[string]string, set func(key string, remove bool)) []manifestChange {
+	var changes []manifestChange
+
+	for _, key := range sortedKeys(desired, live) {
+		want, wanted := desired[key]
+		have, had := live[key]
+
+		switch {
+		case wanted && !had:
+			changes = append(changes, manifestChange{Op: "+", Section: section, Key: key, New: want})
+			set(key, false)
+		case !wanted && had:
+			changes = append(changes, manifestChange{Op: "-", Section: section, Key: key})
+			set(key, true)
+		case want != have:
+			changes = append(changes, manifestChange{Op: "~", Section: section, Key: key, Old: have, New: want})
+			set(key, false)
+		}
+	}
+
+	return changes
+}
+
+// diffNested compares two-level rendered maps, such as healthchecks per process type.
+func diffNested(section string, desired, live map[string]map[string]string, set func(outer, inner string, remove bool)) []manifestChange {
+	var changes []manifestChange
+
+	outerKeys := make(map[string]string)
+	for k := range desired {
+		outerKeys[k] = ""
+	}
+	for k := range live {
+		outerKeys[k] = ""
+	}
+
+	for _, outer := range sortedKeys(outerKeys) {
+		nested := diffFlat(section, desired[outer], live[outer], func(inner string, remove bool) {
+			set(outer, inner, remove)
+		})
+		for i := range nested {
+			nested[i].Key = outer + " " + nested[i].Key
+		}
+		changes = append(changes, nested...)
+	}
+
+	return changes
+}
+
+func renderValues(values map[string]interface{}) map[string]string {
+	rendered := make(map[string]string, len(values))
+	for k, v := range values {
+		rendered[k] = manifestValue(v)
+	}
+	return rendered
+}
+
+func renderStrings(values []string) map[string]string {
+	rendered := make(map[string]string, len(values))
+	for _, v := range values {
+		rendered[v] = ""
+	}
+	return rendered
+}
+
+func renderHealthchecks(healthchecks map[string]*api.Healthchecks) map[string]map[string]string {
+	rendered := make(map[string]map[string]string)
+	for procType, probes := range healthchecks {
+		if probes == nil {
+			continue
+		}
+		rendered[procType] = make(map[string]string)
+		for probeType, probe := range *probes {
+			if probe != nil {
+				rendered[procType][probeType] = jsonValue(probe)
+			}
+		}
+	}
+	return rendered
+}
+
+func renderTolerations(tolerations map[string]map[string]*v1.Toleration) map[string]map[string]string {
+	rendered := make(map[string]map[string]string)
+	for procType, identifiers := range tolerations {
+		rendered[procType] = make(map[string]string)
+		for identifier, toleration := range identifiers {
+			if toleration != nil {
+				rendered[procType][identifier] = jsonValue(toleration)
+			}
+		}
+	}
+	return rendered
+}
+
+func renderAnnotations(annotations map[string]api.Annotation) map[string]map[string]string {
+	rendered := make(map[string]map[string]string)
+	for procType, values := range annotations {
+		rendered[procType] = renderValues(values)
+	}
+	return rendered
+}
+
+func renderCerts(certs map[string][]string) map[string]map[string]string {
+	rendered := make(map[string]map[string]string)
+	for name, certDomains := range certs {
+		rendered[name] = renderStrings(certDomains)
+	}
+	return rendered
+}
+
+func renderAutoscale(autoscale map[string]*api.Autoscale) map[string]string {
+	rendered := make(map[string]string)
+	for procType, rule := range autoscale {
+		if rule != nil {
+			rendered[procType] = jsonValue(rule)
+		}
+	}
+	return rendered
+}
+
+// diffBool compares an optional boolean setting, treating a missing live value as fallback.
+func diffBool(section string, desired, live *bool, fallback bool) []manifestChange {
+	if desired == nil {
+		return nil
+	}
+
+	have := fallback
+	if live != nil {
+		have = *live
+	}
+
+	if *desired == have {
+		return nil
+	}
+
+	return []manifestChange{{
+		Op:      "~",
+		Section: section,
+		Old:     strconv.FormatBool(have),
+		New:     strconv.FormatBool(*desired),
+	}}
+}
+
+// planManifest compares a manifest against the live state of an app. All config changes are
+// collected into a single config update, so applying a plan creates at most one release.
+func planManifest(m Manifest, live appState) manifestPlan {
+	plan := manifestPlan{}
+	configObj := api.Config{}
+	appSettings := api.AppSettings{}
+
+	if m.Config != nil {
+		plan.Changes = append(plan.Changes, diffFlat("config", renderValues(m.Config),
+			renderValues(live.Config.Values), func(key string, remove bool) {
+				if configObj.Values == nil {
+					configObj.Values = make(map[string]interface{})
+				}
+				if remove {
+					configObj.Values[key] = nil
+				} else {
+					configObj.Values[key] = manifestValue(m.Config[key])
+				}
+			})...)
+	}
+
+	if m.Memory != nil {
+		plan.Changes = append(plan.Changes, diffFlat("memory", renderValues(m.Memory),
+			renderValues(live.Config.Memory), func(key string, remove bool) {
+				if configObj.Memory == nil {
+					configObj.Memory = make(map[string]interface{})
+				}
+				if remove {
+					configObj.Memory[key] = nil
+				} else {
+					configObj.Memory[key] = manifestValue(m.Memory[key])
+				}
+			})...)
+	}
+
+	if m.CPU != nil {
+		plan.Changes = append(plan.Changes, diffFlat("cpu", renderValues(m.CPU),
+			renderValues(live.Config.CPU), func(key string, remove bool) {
+				if configObj.CPU == nil {
+					configObj.CPU = make(map[string]interface{})
+				}
+				if remove {
+					configObj.CPU[key] = nil
+				} else {
+					configObj.CPU[key] = manifestValue(m.CPU[key])
+				}
+			})...)
+	}
+
+	if m.Healthchecks != nil {
+		plan.Changes = append(plan.Changes, diffNested("healthchecks", renderHealthchecks(m.Healthchecks),
+			renderHealthchecks(live.Config.Healthcheck), func(procType, probeType string, remove bool) {
+				if configObj.Healthcheck == nil {
+					configObj.Healthcheck = make(map[string]*api.Healthchecks)
+				}
+				probes, ok := configObj.Healthcheck[procType]
+				if !ok {
+					probes = &api.Healthchecks{}
+					configObj.Healthcheck[procType] = probes
+				}
+				if remove {
+					(*probes)[probeType] = nil
+				} else {
+					(*probes)[probeType] = (*m.Healthchecks[procType])[probeType]
+				}
+			})...)
+	}
+
+	if m.Tolerations != nil {
+		plan.Changes = append(plan.Changes, diffNested("tolerations", renderTolerations(m.Tolerations),
+			renderTolerations(live.Config.Tolerations), func(procType, identifier string, remove bool) {
+				if configObj.Tolerations == nil {
+					configObj.Tolerations = make(map[string]map[string]*v1.Toleration)
+				}
+				if configObj.Tolerations[procType] == nil {
+					configObj.Tolerations[procType] = make(map[string]*v1.Toleration)
+				}
+				if remove {
+					configObj.Tolerations[procType][identifier] = nil
+				} else {
+					configObj.Tolerations[procType][identifier] = m.Tolerations[procType][identifier]
+				}
+			})...)
+	}
+
+	if m.Annotations != nil {
+		plan.Changes = append(plan.Changes, diffNested("annotations", renderAnnotations(m.Annotations),
+			renderAnnotations(live.Config.Annotations), func(procType, key string, remove bool) {
+				if configObj.Annotations == nil {
+					configObj.Annotations = make(map[string]api.Annotation)
+				}
+				if configObj.Annotations[procType] == nil {
+					configObj.Annotations[procType] = make(api.Annotation)
+				}
+				if remove {
+					configObj.Annotations[procType][key] = nil
+				} else {
+					configObj.Annotations[procType][key] = manifestValue(m.Annotations[procType][key])
+				}
+			})...)
+	}
+
+	if m.Tags != nil {
+		plan.Changes = append(plan.Changes, diffFlat("tags", renderValues(m.Tags),
+			renderValues(live.Config.Tags), func(key string, remove bool) {
+				if configObj.Tags == nil {
+					configObj.Tags = make(map[string]interface{})
+				}
+				if remove {
+					configObj.Tags[key] = nil
+				} else {
+					configObj.Tags[key] = manifestValue(m.Tags[key])
+				}
+			})...)
+	}
+
+	if m.Labels != nil {
+		plan.Changes = append(plan.Changes, diffFlat("labels", renderValues(m.Labels),
+			renderValues(live.AppSettings.Label), func(key string, remove bool) {
+				if appSettings.Label == nil {
+					appSettings.Label = make(map[string]interface{})
+				}
+				if remove {
+					appSettings.Label[key] = nil
+				} else {
+					appSettings.Label[key] = manifestValue(m.Labels[key])
+				}
+			})...)
+	}
+
+	if m.Autoscale != nil {
+		plan.Changes = append(plan.Changes, diffFlat("autoscale", renderAutoscale(m.Autoscale),
+			renderAutoscale(live.AppSettings.Autoscale), func(procType string, remove bool) {
+				if appSettings.Autoscale == nil {
+					appSettings.Autoscale = make(map[string]*api.Autoscale)
+				}
+				if remove {
+					appSettings.Autoscale[procType] = nil
+				} else {
+					appSettings.Autoscale[procType] = m.Autoscale[procType]
+				}
+			})...)
+	}
+
+	if changes := diffBool("routable", m.Routable, live.AppSettings.Routable, true); changes != nil {
+		plan.Changes = append(plan.Changes, changes...)
+		appSettings.Routable = m.Routable
+	}
+
+	if changes := diffBool("maintenance", m.Maintenance, live.AppSettings.Maintenance, false); changes != nil {
+		plan.Changes = append(plan.Changes, changes...)
+		appSettings.Maintenance = m.Maintenance
+	}
+
+	if m.Whitelist != nil {
+		plan.Changes = append(plan.Changes, diffFlat("whitelist", renderStrings(m.Whitelist),
+			renderStrings(live.Whitelist), func(address string, remove bool) {
+				if remove {
+					plan.RemoveWhitelist = append(plan.RemoveWhitelist, address)
+				} else {
+					plan.AddWhitelist = append(plan.AddWhitelist, address)
+				}
+			})...)
+	}
+
+	if m.Domains != nil {
+		plan.Changes = append(plan.Changes, diffFlat("domains", renderStrings(m.Domains),
+			renderStrings(live.Domains), func(domain string, remove bool) {
+				if remove {
+					plan.RemoveDomains = append(plan.RemoveDomains, domain)
+				} else {
+					plan.AddDomains = append(plan.AddDomains, domain)
+				}
+			})...)
+	}
+
+	if m.Certs != nil {
+		plan.Changes = append(plan.Changes, diffNested("certs", renderCerts(m.Certs),
+			renderCerts(live.Certs), func(name, domain string, remove bool) {
+				if remove {
+					plan.DetachCerts = append(plan.DetachCerts, certDomain{Cert: name, Domain: domain})
+				} else {
+					plan.AttachCerts = append(plan.AttachCerts, certDomain{Cert: name, Domain: domain})
+				}
+			})...)
+	}
+
+	if m.Perms != nil {
+		plan.Changes = append(plan.Changes, diffFlat("perms", renderStrings(m.Perms),
+			renderStrings(live.Perms), func(username string, remove bool) {
+				if remove {
+					plan.RemovePerms = append(plan.RemovePerms, username)
+				} else {
+					plan.AddPerms = append(plan.AddPerms, username)
+				}
+			})...)
+	}
+
+	if changes := diffBool("https_enforced", m.HTTPSEnforced, live.TLS.HTTPSEnforced, false); changes != nil {
+		plan.Changes = append(plan.Changes, changes...)
+		plan.HTTPSEnforced = m.HTTPSEnforced
+	}
+
+	if configObj.Values != nil || configObj.Memory != nil || configObj.CPU != nil ||
+		configObj.Healthcheck != nil || configObj.Tolerations != nil ||
+		configObj.Annotations != nil || configObj.Tags != nil {
+		plan.Config = &configObj
+	}
+
+	if appSettings.Label != nil || appSettings.Autoscale != nil || appSettings.Routable != nil ||
+		appSettings.Maintenance != nil {
+		plan.AppSettings = &appSettings
+	}
+
+	return plan
+}
+
+// fetchAppState reads the parts of an app's live state that the manifest manages. If m is
+// nil, all of it is read.
+func (d *DeisCmd) fetchAppState(s *settings.Settings, appID string, m *Manifest) (appState, error) {
+	live := appState{}
+	var err error
+
+	live.Config, err = config.List(s.Client, appID)
+	if d.checkAPICompatibility(s, err) != nil {
+		return live, err
+	}
+
+	live.AppSettings, err = appsettings.List(s.Client, appID)
+	if d.checkAPICompatibility(s, err) != nil {
+		return live, err
+	}
+
+	if m == nil || m.Whitelist != nil {
+		addresses, err := whitelist.List(s.Client, appID)
+		if d.checkAPICompatibility(s, err) != nil {
+			return live, err
+		}
+		live.Whitelist = addresses.Addresses
+	}
+
+	if m == nil || m.Domains != nil || m.Certs != nil {
+		if live.Domains, err = d.listDomains(s, appID); err != nil {
+			return live, err
+		}
+	}
+
+	if m == nil || m.Certs != nil {
+		if live.Certs, err = d.listCerts(s, live.Domains); err != nil {
+			return live, err
+		}
+	}
+
+	if m == nil || m.Perms != nil {
+		live.Perms, err = perms.List(s.Client, appID)
+		if d.checkAPICompatibility(s, err) != nil {
+			return live, err
+		}
+	}
+
+	if m == nil || m.HTTPSEnforced != nil {
+		live.TLS, err = tls.Info(s.Client, appID)
+		if d.checkAPICompatibility(s, err) != nil {
+			return live, err
+		}
+	}
+
+	return live, nil
+}
+
+// listDomains returns every domain of an app, regardless of the response limit.
+func (d *DeisCmd) listDomains(s *settings.Settings, appID string) ([]string, error) {
+	appDomains, count, err := domains.List(s.Client, appID, s.Limit)
+	if d.checkAPICompatibility(s, err) != nil {
+		return nil, err
+	}
+
+	if count > len(appDomains) {
+		appDomains, _, err = domains.List(s.Client, appID, count)
+		if d.checkAPICompatibility(s, err) != nil {
+			return nil, err
+		}
+	}
+
+	names := []string{}
+	for _, domain := range appDomains {
+		names = append(names, domain.Domain)
+	}
+
+	return names, nil
+}
+
+// listCerts returns the certificates attached to any of the given domains, along with the
+// domains they are attached to.
+func (d *DeisCmd) listCerts(s *settings.Settings, appDomains []string) (map[string][]string, error) {
+	allCerts, count, err := certs.List(s.Client, s.Limit)
+	if d.checkAPICompatibility(s, err) != nil {
+		return nil, err
+	}
+
+	if count > len(allCerts) {
+		allCerts, _, err = certs.List(s.Client, count)
+		if d.checkAPICompatibility(s, err) != nil {
+			return nil, err
+		}
+	}
+
+	owned := renderStrings(appDomains)
+	attached := make(map[string][]string)
+	for _, cert := range allCerts {
+		for _, domain := range cert.Domains {
+			if _, ok := owned[domain]; ok {
+				attached[cert.Name] = append(attached[cert.Name], domain)
+			}
+		}
+	}
+
+	return attached, nil
+}
+
+// applyPlan sends the changes of a plan to the controller.
+func (d *DeisCmd) applyPlan(s *settings.Settings, appID string, plan manifestPlan) error {
+	var err error
+
+	if plan.Config != nil {
+		if _, err = config.Set(s.Client, appID, *plan.Config); d.checkAPICompatibility(s, err) != nil {
+			return err
+		}
+	}
+
+	if plan.AppSettings != nil {
+		if _, err = appsettings.Set(s.Client, appID, *plan.AppSettings); d.checkAPICompatibility(s, err) != nil {
+			return err
+		}
+	}
+
+	if len(plan.AddWhitelist) > 0 {
+		if _, err = whitelist.Add(s.Client, appID, plan.AddWhitelist); d.checkAPICompatibility(s, err) != nil {
+			return err
+		}
+	}
+
+	if len(plan.RemoveWhitelist) > 0 {
+		if err = whitelist.Delete(s.Client, appID, plan.RemoveWhitelist); d.checkAPICompatibility(s, err) != nil {
+			return err
+		}
+	}
+
+	for _, attachment := range plan.DetachCerts {
+		if err = certs.Detach(s.Client, attachment.Cert, attachment.Domain); d.checkAPICompatibility(s, err) != nil {
+			return err
+		}
+	}
+
+	for _, domain := range plan.AddDomains {
+		if _, err = domains.New(s.Client, appID, domain); d.checkAPICompatibility(s, err) != nil {
+			return err
+		}
+	}
+
+	for _, domain := range plan.RemoveDomains {
+		if err = domains.Delete(s.Client, appID, domain); d.checkAPICompatibility(s, err) != nil {
+			return err
+		}
+	}
+
+	for _, attachment := range plan.AttachCerts {
+		if err = certs.Attach(s.Client, attachment.Cert, attachment.Domain); d.checkAPICompatibility(s, err) != nil {
+			return err
+		}
+	}
+
+	for _, username := range plan.AddPerms {
+		if err = perms.New(s.Client, appID, username); d.checkAPICompatibility(s, err) != nil {
+			return err
+		}
+	}
+
+	for _, username := range plan.RemovePerms {
+		if err = perms.Delete(s.Client, appID, username); d.checkAPICompatibility(s, err) != nil {
+			return err
+		}
+	}
+
+	if plan.HTTPSEnforced != nil {
+		if *plan.HTTPSEnforced {
+			_, err = tls.Enable(s.Client, appID)
+		} else {
+			_, err = tls.Disable(s.Client, appID)
+		}
+		if d.checkAPICompatibility(s, err) != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *DeisCmd) readManifest(filename string) (Manifest, error) {
+	var contents []byte
+	var err error
+
+	if filename == "-" {
+		buffer := new(bytes.Buffer)
+		if _, err = buffer.ReadFrom(d.WIn); err != nil {
+			return Manifest{}, err
+		}
+		contents = buffer.Bytes()
+	} else if contents, err = ioutil.ReadFile(filename); err != nil {
+		return Manifest{}, err
+	}
+
+	return parseManifest(contents)
+}
+
+// manifestFromState builds a manifest that manages every section of an app's state.
+func manifestFromState(appID string, live appState) Manifest {
+	routable := live.AppSettings.Routable == nil || *live.AppSettings.Routable
+	maintenance := live.AppSettings.Maintenance != nil && *live.AppSettings.Maintenance
+	httpsEnforced := live.TLS.HTTPSEnforced != nil && *live.TLS.HTTPSEnforced
+
+	return Manifest{
+		App:           appID,
+		Config:        live.Config.Values,
+		Memory:        live.Config.Memory,
+		CPU:           live.Config.CPU,
+		Healthchecks:  live.Config.Healthcheck,
+		Tolerations:   live.Config.Tolerations,
+		Annotations:   live.Config.Annotations,
+		Tags:          live.Config.Tags,
+		Labels:        live.AppSettings.Label,
+		Autoscale:     live.AppSettings.Autoscale,
+		Whitelist:     live.Whitelist,
+		Domains:       live.Domains,
+		Certs:         live.Certs,
+		Perms:         live.Perms,
+		Routable:      &routable,
+		HTTPSEnforced: &httpsEnforced,
+		Maintenance:   &maintenance,
+	}
+}
+
+// marshalManifest encodes a manifest as JSON if --output=json was given and as YAML otherwise.
+func (d *DeisCmd) marshalManifest(m Manifest) ([]byte, error) {
+	if d.Output == OutputJSON {
+		out, err := json.MarshalIndent(m, "", "  ")
+		return append(out, '\n'), err
+	}
+
+	return yaml.Marshal(m)
+}
+
+// AppExport writes the live state of an app as a manifest, to a file or to stdout.
+func (d *DeisCmd) AppExport(appID, filename string) error {
+	s, appID, err := load(d.ConfigFile, appID)
+	if err != nil {
+		return err
+	}
+
+	live, err := d.fetchAppState(s, appID, nil)
+	if err != nil {
+		return err
+	}
+
+	out, err := d.marshalManifest(manifestFromState(appID, live))
+	if err != nil {
+		return err
+	}
+
+	if filename == "" {
+		_, err = d.WOut.Write(out)
+		return err
+	}
+
+	// The manifest contains the app's config values, keep it private.
+	if err = ioutil.WriteFile(filename, out, 0600); err != nil {
+		return err
+	}
+
+	d.Printf("Exported %s to %s\n", appID, filename)
+	return nil
+}
+
+// cloneManifest turns the state of an app into a manifest for a copy of it called appID.
+// The source's default domain is replaced by the copy's, and sections that can't be shared
+// with the source are left out. Config keys matching secrets are left out too.
+func cloneManifest(source, appID string, live appState, secrets []string, excludeDomains,
+	sameController bool) (Manifest, error) {
+	m := manifestFromState(appID, live)
+
+	if len(secrets) > 0 {
+		values := make(map[string]interface{})
+		for key, value := range m.Config {
+			if !isSecretKey(key, secrets) {
+				values[key] = value
+			}
+		}
+		m.Config = values
+	}
+
+	if excludeDomains {
+		m.Domains = nil
+		m.Certs = nil
+	} else {
+		customDomains := []string{}
+		for _, domain := range live.Domains {
+			if domain != source {
+				customDomains = append(customDomains, domain)
+			}
+		}
+
+		if sameController && len(customDomains) > 0 {
+			return Manifest{}, fmt.Errorf("the domains of %s can't be used by another app on the same controller, use --exclude-domains", source)
+		}
+
+		m.Domains = append([]string{appID}, customDomains...)
+	}
+
+	// Collaborators and certificates may not exist on another controller, and attaching a
+	// missing certificate would fail after the app is created.
+	if !sameController {
+		m.Perms = nil
+		m.Certs = nil
+	}
+
+	return m, nil
+}
+
+// currentBuild returns the build used by the latest release of an app.
+func (d *DeisCmd) currentBuild(s *settings.Settings, appID string) (api.Build, error) {
+	appReleases, _, err := releases.List(s.Client, appID, 1)
+	if d.checkAPICompatibility(s, err) != nil {
+		return api.Build{}, err
+	}
+
+	if len(appReleases) == 0 || appReleases[0].Build == "" {
+		return api.Build{}, fmt.Errorf("%s has not been deployed yet", appID)
+	}
+
+	appBuilds, count, err := builds.List(s.Client, appID, s.Limit)
+	if d.checkAPICompatibility(s, err) != nil {
+		return api.Build{}, err
+	}
+
+	if count > len(appBuilds) {
+		appBuilds, _, err = builds.List(s.Client, appID, count)
+		if d.checkAPICompatibility(s, err) != nil {
+			return api.Build{}, err
+		}
+	}
+
+	for _, build := range appBuilds {
+		if build.UUID == appReleases[0].Build {
+			return build, nil
+		}
+	}
+
+	return api.Build{}, fmt.Errorf("build %s of %s not found", appReleases[0].Build, appID)
+}
+
+// AppClone creates a new app with the settings of an existing one, optionally on the
+// controller of another profile.
+func (d *DeisCmd) AppClone(source, appID, targetProfile string, excludeSecrets, excludeDomains, deploy bool) error {
+	s, source, err := load(d.ConfigFile, source)
+	if err != nil {
+		return err
+	}
+
+	target := s
+	if targetProfile != "" {
+		if target, err = settings.Load(targetProfile); err != nil {
+			return err
+		}
+	}
+
+	sameController := target.Client.ControllerURL.String() == s.Client.ControllerURL.String()
+
+	live, err := d.fetchAppState(s, source, nil)
+	if err != nil {
+		return err
+	}
+
+	var secrets []string
+	if excludeSecrets {
+		secrets = secretPatterns(s)
+	}
+
+	m, err := cloneManifest(source, appID, live, secrets, excludeDomains, sameController)
+	if err != nil {
+		return err
+	}
+
+	var build api.Build
+	if deploy {
+		if build, err = d.currentBuild(s, source); err != nil {
+			return err
+		}
+	}
+
+	d.Printf("Creating %s... ", appID)
+	quit := progress(d.WOut)
+	_, err = apps.New(target.Client, appID)
+	quit <- true
+	<-quit
+	if d.checkAPICompatibility(target, err) != nil {
+		return err
+	}
+	d.Println("done")
+
+	d.Printf("Copying settings from %s... ", source)
+	quit = progress(d.WOut)
+	created, err := d.fetchAppState(target, appID, &m)
+	if err == nil {
+		err = d.applyPlan(target, appID, planManifest(m, created))
+	}
+	quit <- true
+	<-quit
+	if err != nil {
+		return err
+	}
+	d.Println("done")
+
+	if deploy {
+		d.Printf("Deploying %s... ", build.Image)
+		quit = progress(d.WOut)
+		_, err = builds.New(target.Client, appID, build.Image, build.Procfile, build.Sidecarfile)
+		quit <- true
+		<-quit
+		if d.checkAPICompatibility(target, err) != nil {
+			return err
+		}
+		d.Println("done")
+	}
+
+	return nil
+}
+
+// AppApply brings an app to the state described by a manifest file. The values of secret
+// config keys are masked in the plan unless reveal is true.
+func (d *DeisCmd) AppApply(appID, filename string, reveal bool) error {
+	m, err := d.readManifest(filename)
+	if err != nil {
+		return err
+	}
+
+	if appID == "" {
+		appID = m.App
+	}
+
+	s, appID, err := load(d.ConfigFile, appID)
+	if err != nil {
+		return err
+	}
+
+	live, err := d.fetchAppState(s, appID, &m)
+	if err != nil {
+		return err
+	}
+
+	plan := planManifest(m, live)
+
+	d.Printf("=== %s Plan\n", appID)
+	if len(plan.Changes) == 0 {
+		d.Println("No changes, the app already matches the manifest.")
+		return nil
+	}
+
+	changes := plan.Changes
+	if !reveal {
+		changes = maskChanges(changes, "config", secretPatterns(s))
+	}
+
+	for _, change := range changes {
+		d.Println(change)
+	}
+	d.Println()
+
+	if d.DryRun {
+		d.Println("Nothing was sent to the controller, as --dry-run was given.")
+		return nil
+	}
+
+	d.Print("Applying manifest... ")
+
+	quit := progress(d.WOut)
+	err = d.applyPlan(s, appID, plan)
+	quit <- true
+	<-quit
+	if err != nil {
+		return err
+	}
+
+	d.Println("done")
+	return nil
+}
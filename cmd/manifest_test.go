@@ -0,0 +1,485 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arschles/assert"
+	"github.com/deis/controller-sdk-go/api"
+	"github.com/deis/workflow-cli/pkg/testutil"
+)
+
+func TestParseManifest(t *testing.T) {
+	t.Parallel()
+
+	m, err := parseManifest([]byte(`app: foo
+config:
+  PORT: 5000
+  WORKERS: 1000000
+  DEBUG: false
+routable: false
+domains:
+  - foo.example.com
+`))
+	assert.NoErr(t, err)
+	assert.Equal(t, m.App, "foo", "app")
+	assert.Equal(t, renderValues(m.Config), map[string]string{
+		"PORT":    "5000",
+		"WORKERS": "1000000",
+		"DEBUG":   "false",
+	}, "config")
+	assert.Equal(t, *m.Routable, false, "routable")
+	assert.Equal(t, m.Domains, []string{"foo.example.com"}, "domains")
+	assert.Equal(t, m.Memory == nil, true, "unmanaged memory")
+
+	_, err = parseManifest([]byte("config: ["))
+	assert.ExistsErr(t, err, "invalid manifest")
+}
+
+func TestPlanManifest(t *testing.T) {
+	t.Parallel()
+
+	falseVal := false
+	live := appState{
+		Config: api.Config{
+			Values: map[string]interface{}{"PORT": "4000", "OLD": "x", "SAME": "y"},
+			Memory: map[string]interface{}{"web": "256M"},
+		},
+		AppSettings: api.AppSettings{
+			Label: map[string]interface{}{"team": "payments"},
+		},
+		Domains: []string{"foo", "old.example.com"},
+	}
+
+	m := Manifest{
+		Config:      map[string]interface{}{"PORT": float64(5000), "SAME": "y", "NEW": "z"},
+		Labels:      map[string]interface{}{"team": "payments"},
+		Domains:     []string{"foo", "foo.example.com"},
+		Maintenance: &falseVal,
+		Routable:    &falseVal,
+	}
+
+	plan := planManifest(m, live)
+
+	var lines []string
+	for _, change := range plan.Changes {
+		lines = append(lines, change.String())
+	}
+
+	assert.Equal(t, lines, []string{
+		"+ config NEW=z",
+		"- config OLD",
+		"~ config PORT=5000 (was 4000)",
+		"~ routable=false (was true)",
+		"+ domains foo.example.com",
+		"- domains old.example.com",
+	}, "changes")
+
+	assert.Equal(t, plan.Config, &api.Config{
+		Values: map[string]interface{}{"NEW": "z", "OLD": nil, "PORT": "5000"},
+	}, "config payload")
+	assert.Equal(t, plan.AppSettings, &api.AppSettings{Routable: &falseVal}, "settings payload")
+	assert.Equal(t, plan.AddDomains, []string{"foo.example.com"}, "added domains")
+	assert.Equal(t, plan.RemoveDomains, []string{"old.example.com"}, "removed domains")
+	assert.Equal(t, plan.HTTPSEnforced == nil, true, "https_enforced")
+}
+
+func TestPlanManifestHealthchecks(t *testing.T) {
+	t.Parallel()
+
+	live := appState{
+		Config: api.Config{
+			Healthcheck: map[string]*api.Healthchecks{
+				"web": {
+					"livenessProbe":  &api.Healthcheck{InitialDelaySeconds: 10},
+					"readinessProbe": &api.Healthcheck{InitialDelaySeconds: 5},
+				},
+			},
+		},
+	}
+
+	m := Manifest{
+		Healthchecks: map[string]*api.Healthchecks{
+			"web": {
+				"livenessProbe": &api.Healthcheck{InitialDelaySeconds: 10},
+			},
+		},
+	}
+
+	plan := planManifest(m, live)
+
+	assert.Equal(t, len(plan.Changes), 1, "changes")
+	assert.Equal(t, plan.Changes[0].String(), "- healthchecks web readinessProbe", "change")
+	assert.Equal(t, plan.Config, &api.Config{
+		Healthcheck: map[string]*api.Healthchecks{
+			"web": {"readinessProbe": nil},
+		},
+	}, "config payload")
+}
+
+func TestAppApply(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "manifest")
+	assert.NoErr(t, err)
+	defer os.RemoveAll(dir)
+
+	manifest := filepath.Join(dir, "app.yaml")
+	assert.NoErr(t, ioutil.WriteFile(manifest, []byte(`app: foo
+config:
+  PORT: 5000
+  API_TOKEN: new
+domains:
+  - foo.example.com
+`), 0644))
+
+	server.Mux.HandleFunc("/v2/apps/foo/config/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		if r.Method == "POST" {
+			testutil.AssertBody(t, api.Config{
+				Values: map[string]interface{}{"API_TOKEN": "new", "OLD": nil, "PORT": "5000"},
+			}, r)
+		}
+		fmt.Fprintf(w, `{"app": "foo", "values": {"PORT": "4000", "OLD": "x", "API_TOKEN": "old"}}`)
+	})
+
+	server.Mux.HandleFunc("/v2/apps/foo/settings/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		if r.Method == "POST" {
+			t.Error("settings should not be updated")
+		}
+		fmt.Fprintf(w, `{"app": "foo", "maintenance": false, "routable": true}`)
+	})
+
+	server.Mux.HandleFunc("/v2/apps/foo/domains/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		if r.Method == "POST" {
+			testutil.AssertBody(t, api.DomainCreateRequest{Domain: "foo.example.com"}, r)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte("{}"))
+			return
+		}
+		fmt.Fprintf(w, `{"count": 1, "results": [{"app": "foo", "domain": "foo"}]}`)
+	})
+
+	server.Mux.HandleFunc("/v2/apps/foo/domains/foo", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		assert.Equal(t, r.Method, "DELETE", "method")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	var b bytes.Buffer
+	cmdr := DeisCmd{WOut: &b, ConfigFile: cf}
+
+	err = cmdr.AppApply("", manifest, false)
+	assert.NoErr(t, err)
+
+	assert.Equal(t, testutil.StripProgress(b.String()), `=== foo Plan
+~ config API_TOKEN=*** (was ***)
+- config OLD
+~ config PORT=5000 (was 4000)
+- domains foo
++ domains foo.example.com
+
+Applying manifest... done
+`, "output")
+}
+
+func TestAppApplyNoChanges(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	server.Mux.HandleFunc("/v2/apps/bar/config/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		if r.Method == "POST" {
+			t.Error("config should not be updated")
+		}
+		fmt.Fprintf(w, `{"app": "bar", "values": {"PORT": "5000"}}`)
+	})
+
+	server.Mux.HandleFunc("/v2/apps/bar/settings/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"app": "bar", "maintenance": false, "routable": true}`)
+	})
+
+	var b bytes.Buffer
+	cmdr := DeisCmd{WOut: &b, WIn: bytes.NewBufferString(`{"config": {"PORT": 5000}}`), ConfigFile: cf}
+
+	err = cmdr.AppApply("bar", "-", false)
+	assert.NoErr(t, err)
+
+	assert.Equal(t, b.String(), `=== bar Plan
+No changes, the app already matches the manifest.
+`, "output")
+}
+
+func TestAppExport(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	server.Mux.HandleFunc("/v2/apps/foo/config/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{
+	"app": "foo",
+	"values": {"PORT": "5000"},
+	"memory": {"web": "512M"},
+	"cpu": {},
+	"tags": {},
+	"registry": {},
+	"uuid": "de1bf5b5-4a72-4f94-a10c-d2a3741cdf75"
+}`)
+	})
+
+	server.Mux.HandleFunc("/v2/apps/foo/settings/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"app": "foo", "routable": false, "label": {"team": "payments"}}`)
+	})
+
+	server.Mux.HandleFunc("/v2/apps/foo/whitelist/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"addresses": ["10.0.0.0/8"]}`)
+	})
+
+	server.Mux.HandleFunc("/v2/apps/foo/domains/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"count": 2, "results": [{"domain": "foo"}, {"domain": "foo.example.com"}]}`)
+	})
+
+	server.Mux.HandleFunc("/v2/certs/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"count": 2, "results": [
+	{"name": "foo-cert", "domains": ["foo.example.com"]},
+	{"name": "other-cert", "domains": ["other.example.com"]}
+]}`)
+	})
+
+	server.Mux.HandleFunc("/v2/apps/foo/perms/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"users": ["bar"]}`)
+	})
+
+	server.Mux.HandleFunc("/v2/apps/foo/tls/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"app": "foo", "https_enforced": true}`)
+	})
+
+	var b bytes.Buffer
+	cmdr := DeisCmd{WOut: &b, ConfigFile: cf}
+
+	err = cmdr.AppExport("foo", "")
+	assert.NoErr(t, err)
+
+	assert.Equal(t, b.String(), `app: foo
+certs:
+  foo-cert:
+  - foo.example.com
+config:
+  PORT: "5000"
+domains:
+- foo
+- foo.example.com
+https_enforced: true
+labels:
+  team: payments
+maintenance: false
+memory:
+  web: 512M
+perms:
+- bar
+routable: false
+whitelist:
+- 10.0.0.0/8
+`, "output")
+
+	// --output=json describes the same manifest.
+	m, err := parseManifest(b.Bytes())
+	assert.NoErr(t, err)
+
+	b.Reset()
+	cmdr.Output = OutputJSON
+	err = cmdr.AppExport("foo", "")
+	assert.NoErr(t, err)
+
+	jsonManifest, err := parseManifest(b.Bytes())
+	assert.NoErr(t, err)
+	assert.Equal(t, jsonManifest, m, "json manifest")
+
+	dir, err := ioutil.TempDir("", "manifest")
+	assert.NoErr(t, err)
+	defer os.RemoveAll(dir)
+
+	b.Reset()
+	err = cmdr.AppExport("foo", filepath.Join(dir, "foo.json"))
+	assert.NoErr(t, err)
+	assert.Equal(t, b.String(), fmt.Sprintf("Exported foo to %s\n", filepath.Join(dir, "foo.json")), "output")
+}
+
+func TestCloneManifest(t *testing.T) {
+	t.Parallel()
+
+	live := appState{
+		Config: api.Config{
+			Values: map[string]interface{}{"PORT": "5000", "DB_PASSWORD": "hunter2"},
+		},
+		Domains: []string{"foo", "foo.example.com"},
+		Certs:   map[string][]string{"foo-cert": {"foo.example.com"}},
+		Perms:   []string{"baz"},
+	}
+
+	_, err := cloneManifest("foo", "bar", live, nil, false, true)
+	assert.Err(t, errors.New("the domains of foo can't be used by another app on the same controller, use --exclude-domains"), err)
+
+	m, err := cloneManifest("foo", "bar", live, defaultSecretPatterns, true, true)
+	assert.NoErr(t, err)
+	assert.Equal(t, m.App, "bar", "app")
+	assert.Equal(t, m.Config, map[string]interface{}{"PORT": "5000"}, "config")
+	assert.Equal(t, m.Domains == nil, true, "domains")
+	assert.Equal(t, m.Certs == nil, true, "certs")
+	assert.Equal(t, m.Perms, []string{"baz"}, "perms")
+
+	m, err = cloneManifest("foo", "bar", live, nil, false, false)
+	assert.NoErr(t, err)
+	assert.Equal(t, m.Config, map[string]interface{}{"PORT": "5000", "DB_PASSWORD": "hunter2"}, "config")
+	assert.Equal(t, m.Domains, []string{"bar", "foo.example.com"}, "domains")
+	assert.Equal(t, m.Certs == nil, true, "certs")
+	assert.Equal(t, m.Perms == nil, true, "perms")
+}
+
+func TestAppClone(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	server.Mux.HandleFunc("/v2/apps/foo/config/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"app": "foo", "values": {"PORT": "5000", "DB_PASSWORD": "hunter2"}}`)
+	})
+	server.Mux.HandleFunc("/v2/apps/foo/settings/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"app": "foo", "routable": true, "label": {"team": "payments"}}`)
+	})
+	server.Mux.HandleFunc("/v2/apps/foo/whitelist/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"addresses": []}`)
+	})
+	server.Mux.HandleFunc("/v2/apps/foo/domains/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"count": 1, "results": [{"domain": "foo"}]}`)
+	})
+	server.Mux.HandleFunc("/v2/certs/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"count": 0, "results": []}`)
+	})
+	server.Mux.HandleFunc("/v2/apps/foo/perms/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"users": ["baz"]}`)
+	})
+	server.Mux.HandleFunc("/v2/apps/foo/tls/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"app": "foo", "https_enforced": false}`)
+	})
+	server.Mux.HandleFunc("/v2/apps/foo/releases/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"count": 3, "results": [{"app": "foo", "version": 3, "build": "b2"}]}`)
+	})
+	server.Mux.HandleFunc("/v2/apps/foo/builds/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"count": 2, "results": [
+	{"app": "foo", "uuid": "b3", "image": "registry/foo:v4"},
+	{"app": "foo", "uuid": "b2", "image": "registry/foo:v3", "procfile": {"web": "./run"}}
+]}`)
+	})
+
+	server.Mux.HandleFunc("/v2/apps/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		assert.Equal(t, r.Method, "POST", "method")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"id": "bar", "owner": "test"}`)
+	})
+	server.Mux.HandleFunc("/v2/apps/bar/config/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		if r.Method == "POST" {
+			testutil.AssertBody(t, api.Config{Values: map[string]interface{}{"PORT": "5000"}}, r)
+		}
+		fmt.Fprintf(w, `{"app": "bar", "values": {}}`)
+	})
+	server.Mux.HandleFunc("/v2/apps/bar/settings/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		if r.Method == "POST" {
+			testutil.AssertBody(t, api.AppSettings{Label: map[string]interface{}{"team": "payments"}}, r)
+		}
+		fmt.Fprintf(w, `{"app": "bar", "routable": true}`)
+	})
+	server.Mux.HandleFunc("/v2/apps/bar/whitelist/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"addresses": []}`)
+	})
+	server.Mux.HandleFunc("/v2/apps/bar/domains/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"count": 1, "results": [{"domain": "bar"}]}`)
+	})
+	server.Mux.HandleFunc("/v2/apps/bar/perms/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		if r.Method == "POST" {
+			assert.Equal(t, readBody(t, r), `{"username":"baz"}`, "body")
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		fmt.Fprintf(w, `{"users": []}`)
+	})
+	server.Mux.HandleFunc("/v2/apps/bar/tls/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"app": "bar", "https_enforced": false}`)
+	})
+	server.Mux.HandleFunc("/v2/apps/bar/builds/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		assert.Equal(t, r.Method, "POST", "method")
+		testutil.AssertBody(t, api.CreateBuildRequest{
+			Image:    "registry/foo:v3",
+			Procfile: map[string]string{"web": "./run"},
+		}, r)
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{}`)
+	})
+
+	var b bytes.Buffer
+	cmdr := DeisCmd{WOut: &b, ConfigFile: cf}
+
+	err = cmdr.AppClone("foo", "bar", "", true, false, true)
+	assert.NoErr(t, err)
+
+	assert.Equal(t, testutil.StripProgress(b.String()), `Creating bar... done
+Copying settings from foo... done
+Deploying registry/foo:v3... done
+`, "output")
+}
+
+func readBody(t *testing.T, r *http.Request) string {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
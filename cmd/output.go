@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ghodss/yaml"
+)
+
+// Output formats accepted by the global --output flag.
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+)
+
+// ValidOutputFormat returns an error if format is not a supported --output value.
+func ValidOutputFormat(format string) error {
+	switch format {
+	case "", OutputTable, OutputJSON, OutputYAML:
+		return nil
+	default:
+		return fmt.Errorf("unknown output format %s, must be one of: %s, %s, %s",
+			format, OutputTable, OutputJSON, OutputYAML)
+	}
+}
+
+// structured returns true if the user asked for machine-readable output.
+func (d *DeisCmd) structured() bool {
+	return d.Output == OutputJSON || d.Output == OutputYAML
+}
+
+// printStructured writes v to the output writer as JSON or YAML, depending on --output.
+func (d *DeisCmd) printStructured(v interface{}) error {
+	var out []byte
+	var err error
+
+	if d.Output == OutputYAML {
+		out, err = yaml.Marshal(v)
+	} else {
+		out, err = json.MarshalIndent(v, "", "  ")
+		out = append(out, '\n')
+	}
+
+	if err != nil {
+		return err
+	}
+
+	_, err = d.WOut.Write(out)
+	return err
+}
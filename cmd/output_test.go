@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/arschles/assert"
+	"github.com/deis/controller-sdk-go/api"
+)
+
+func TestValidOutputFormat(t *testing.T) {
+	t.Parallel()
+
+	for _, format := range []string{"", "table", "json", "yaml"} {
+		assert.NoErr(t, ValidOutputFormat(format))
+	}
+
+	assert.ExistsErr(t, ValidOutputFormat("xml"), "output format")
+}
+
+func TestPrintStructured(t *testing.T) {
+	t.Parallel()
+
+	var b bytes.Buffer
+	release := api.Release{App: "foo", Version: 2, Summary: "bar deployed"}
+
+	cmdr := DeisCmd{WOut: &b, Output: OutputJSON}
+	assert.NoErr(t, cmdr.printStructured(release))
+	assert.Equal(t, b.String(), `{
+  "app": "foo",
+  "config": "",
+  "created": "",
+  "owner": "",
+  "summary": "bar deployed",
+  "updated": "",
+  "uuid": "",
+  "version": 2
+}
+`, "json")
+
+	b.Reset()
+	cmdr = DeisCmd{WOut: &b, Output: OutputYAML}
+	assert.NoErr(t, cmdr.printStructured(release))
+	assert.Equal(t, b.String(), `app: foo
+config: ""
+created: ""
+owner: ""
+summary: bar deployed
+updated: ""
+uuid: ""
+version: 2
+`, "yaml")
+}
@@ -26,10 +26,14 @@ func (d *DeisCmd) PermsList(appID string, admin bool, results int) error {
 		users, err = perms.List(s.Client, appID)
 	}
 
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
+	if d.structured() {
+		return d.printStructured(users)
+	}
+
 	if admin {
 		d.Printf("=== Administrators%s", limitCount(len(users), count))
 	} else {
@@ -60,7 +64,7 @@ func (d *DeisCmd) PermCreate(appID string, username string, admin bool) error {
 		err = perms.New(s.Client, appID, username)
 	}
 
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
@@ -86,7 +90,7 @@ func (d *DeisCmd) PermDelete(appID, username string, admin bool) error {
 		err = perms.Delete(s.Client, appID, username)
 	}
 
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// processTypePattern matches process type names, such as web or background-worker. It's
+// shared with parsePsTargets, so that a Procfile only has types that can be scaled.
+const processTypePattern = `[a-z0-9]+(?:-[a-z0-9]+)*`
+
+var (
+	processTypeRegex = regexp.MustCompile(`^` + processTypePattern + `$`)
+	// containerNameRegex matches the names Kubernetes allows for containers.
+	containerNameRegex = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+)
+
+// containerFields are the fields of a Kubernetes container spec, which a sidecar is.
+var containerFields = map[string]bool{
+	"name": true, "image": true, "command": true, "args": true, "workingDir": true,
+	"ports": true, "envFrom": true, "env": true, "resources": true, "volumeMounts": true,
+	"volumeDevices": true, "livenessProbe": true, "readinessProbe": true, "startupProbe": true,
+	"lifecycle": true, "terminationMessagePath": true, "terminationMessagePolicy": true,
+	"imagePullPolicy": true, "securityContext": true, "stdin": true, "stdinOnce": true, "tty": true,
+}
+
+var imagePullPolicies = map[string]bool{"Always": true, "IfNotPresent": true, "Never": true}
+
+// loadBuildFiles parses a Procfile and a Sidecarfile from YAML strings, or from the files in
+// the current directory if the strings are empty. Missing files are empty.
+func loadBuildFiles(procfile, sidecarfile string) (map[string]string, map[string]interface{}, error) {
+	procfileMap := make(map[string]string)
+	sidecarfileMap := make(map[string]interface{})
+
+	contents, err := buildFile(procfile, "Procfile")
+	if err != nil {
+		return nil, nil, err
+	} else if contents != nil {
+		if procfileMap, err = parseProcfile(contents); err != nil {
+			return nil, nil, fmt.Errorf("Procfile: %v", err)
+		}
+	}
+
+	if contents, err = buildFile(sidecarfile, "Sidecarfile"); err != nil {
+		return nil, nil, err
+	} else if contents != nil {
+		if sidecarfileMap, err = parseSidecarfile(contents); err != nil {
+			return nil, nil, fmt.Errorf("Sidecarfile: %v", err)
+		}
+	}
+
+	return procfileMap, sidecarfileMap, nil
+}
+
+// buildFile returns value if it isn't empty, or else the contents of the file name in the
+// current directory, or nil if there's no such file.
+func buildFile(value, name string) ([]byte, error) {
+	if value != "" {
+		return []byte(value), nil
+	}
+
+	if _, err := os.Stat(name); err != nil {
+		return nil, nil
+	}
+
+	return ioutil.ReadFile(name)
+}
+
+// validateBuildFiles checks the process types and commands of a Procfile and the container
+// specs of a Sidecarfile, returning every problem found in a single error.
+func validateBuildFiles(procfile map[string]string, sidecarfile map[string]interface{}) error {
+	problems := append(validateProcfile(procfile), validateSidecarfile(sidecarfile, procfile)...)
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("found %d problem(s):\n  %s", len(problems), strings.Join(problems, "\n  "))
+}
+
+func validateProcfile(procfile map[string]string) []string {
+	var problems []string
+
+	for _, procType := range sortedTypes(procfile) {
+		if !processTypeRegex.MatchString(procType) {
+			problems = append(problems, fmt.Sprintf(
+				"Procfile: process type %q must be lowercase letters, numbers and dashes, such as web or background-worker",
+				procType))
+		}
+
+		if strings.TrimSpace(procfile[procType]) == "" {
+			problems = append(problems, fmt.Sprintf("Procfile: process type %q has an empty command", procType))
+		}
+	}
+
+	return problems
+}
+
+func validateSidecarfile(sidecarfile map[string]interface{}, procfile map[string]string) []string {
+	var problems []string
+
+	for _, procType := range sidecarTypes(sidecarfile) {
+		prefix := "Sidecarfile: " + procType
+
+		if !processTypeRegex.MatchString(procType) {
+			problems = append(problems, fmt.Sprintf(
+				"Sidecarfile: process type %q must be lowercase letters, numbers and dashes, such as web or background-worker",
+				procType))
+		} else if _, ok := procfile[procType]; len(procfile) > 0 && !ok {
+			problems = append(problems, fmt.Sprintf("Sidecarfile: process type %q isn't in the Procfile", procType))
+		}
+
+		containers, ok := sidecarfile[procType].([]interface{})
+		if !ok {
+			problems = append(problems, prefix+": must be a list of containers")
+			continue
+		}
+
+		names := make(map[string]bool)
+		for i, container := range containers {
+			spec, ok := container.(map[string]interface{})
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s[%d]: must be a container spec", prefix, i))
+				continue
+			}
+
+			for _, problem := range validateContainer(spec) {
+				problems = append(problems, fmt.Sprintf("%s[%d]: %s", prefix, i, problem))
+			}
+
+			if name, ok := spec["name"].(string); ok {
+				if names[name] {
+					problems = append(problems, fmt.Sprintf("%s[%d]: name %q is used by another sidecar", prefix, i, name))
+				}
+				names[name] = true
+			}
+		}
+	}
+
+	return problems
+}
+
+// validateContainer checks the fields of a sidecar's container spec.
+func validateContainer(spec map[string]interface{}) []string {
+	var problems []string
+
+	var fields []string
+	for field := range spec {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		if !containerFields[field] {
+			problems = append(problems, fmt.Sprintf("unknown field %q", field))
+		}
+	}
+
+	if name, ok := spec["name"].(string); !ok || name == "" {
+		problems = append(problems, `missing "name"`)
+	} else if len(name) > 63 || !containerNameRegex.MatchString(name) {
+		problems = append(problems, fmt.Sprintf(
+			"name %q must be at most 63 lowercase letters, numbers and dashes, starting and ending with a letter or number",
+			name))
+	}
+
+	if image, ok := spec["image"].(string); !ok || strings.TrimSpace(image) == "" {
+		problems = append(problems, `missing "image"`)
+	}
+
+	for _, field := range []string{"command", "args"} {
+		if value, ok := spec[field]; ok && !isStringList(value) {
+			problems = append(problems, fmt.Sprintf("%q must be a list of strings", field))
+		}
+	}
+
+	if policy, ok := spec["imagePullPolicy"]; ok && !imagePullPolicies[fmt.Sprint(policy)] {
+		problems = append(problems, fmt.Sprintf(`"imagePullPolicy" must be Always, IfNotPresent or Never, not %v`, policy))
+	}
+
+	if value, ok := spec["env"]; ok {
+		env, ok := value.([]interface{})
+		if !ok {
+			problems = append(problems, `"env" must be a list of variables`)
+		}
+		for i, variable := range env {
+			if v, ok := variable.(map[string]interface{}); !ok || v["name"] == nil || v["name"] == "" {
+				problems = append(problems, fmt.Sprintf(`env[%d]: missing "name"`, i))
+			}
+		}
+	}
+
+	if value, ok := spec["ports"]; ok {
+		ports, ok := value.([]interface{})
+		if !ok {
+			problems = append(problems, `"ports" must be a list of ports`)
+		}
+		for i, port := range ports {
+			p, _ := port.(map[string]interface{})
+			number, ok := p["containerPort"].(float64)
+			if !ok || number != float64(int(number)) || number < 1 || number > 65535 {
+				problems = append(problems, fmt.Sprintf(`ports[%d]: "containerPort" must be a number from 1 to 65535`, i))
+			}
+		}
+	}
+
+	return problems
+}
+
+func isStringList(value interface{}) bool {
+	list, ok := value.([]interface{})
+	if !ok {
+		return false
+	}
+
+	for _, item := range list {
+		if _, ok := item.(string); !ok {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/arschles/assert"
+)
+
+func TestValidateBuildFiles(t *testing.T) {
+	t.Parallel()
+
+	procfile, err := parseProcfile([]byte(`web: ./server
+Worker: ./work
+cron: " "
+`))
+	assert.NoErr(t, err)
+
+	sidecarfile, err := parseSidecarfile([]byte(`web:
+- name: proxy
+  image: envoy:latest
+  ports:
+  - containerPort: 70000
+- name: proxy
+  image: ""
+  comand: ["envoy"]
+wrker:
+- name: Logger
+  image: fluentd
+  args: "-v"
+  imagePullPolicy: Sometimes
+cron: busybox
+`))
+	assert.NoErr(t, err)
+
+	err = validateBuildFiles(procfile, sidecarfile)
+	assert.Err(t, errors.New(`found 11 problem(s):
+  Procfile: process type "Worker" must be lowercase letters, numbers and dashes, such as web or background-worker
+  Procfile: process type "cron" has an empty command
+  Sidecarfile: cron: must be a list of containers
+  Sidecarfile: web[0]: ports[0]: "containerPort" must be a number from 1 to 65535
+  Sidecarfile: web[1]: unknown field "comand"
+  Sidecarfile: web[1]: missing "image"
+  Sidecarfile: web[1]: name "proxy" is used by another sidecar
+  Sidecarfile: process type "wrker" isn't in the Procfile
+  Sidecarfile: wrker[0]: name "Logger" must be at most 63 lowercase letters, numbers and dashes, starting and ending with a letter or number
+  Sidecarfile: wrker[0]: "args" must be a list of strings
+  Sidecarfile: wrker[0]: "imagePullPolicy" must be Always, IfNotPresent or Never, not Sometimes`), err)
+
+	sidecarfile, err = parseSidecarfile([]byte(`web:
+- name: proxy
+  image: envoy:latest
+  args: ["--config", "/etc/envoy.yaml"]
+  env:
+  - name: LOG_LEVEL
+    value: info
+  ports:
+  - containerPort: 9901
+`))
+	assert.NoErr(t, err)
+	assert.NoErr(t, validateBuildFiles(map[string]string{"web": "./server"}, sidecarfile))
+	assert.NoErr(t, validateBuildFiles(map[string]string{}, sidecarfile))
+}
+
+func TestBuildsValidate(t *testing.T) {
+	t.Parallel()
+
+	var b bytes.Buffer
+	cmdr := DeisCmd{WOut: &b}
+
+	err := cmdr.BuildsValidate("web: ./server\nworker: ./work\n", "web:\n- name: proxy\n  image: envoy:latest\n")
+	assert.NoErr(t, err)
+	assert.Equal(t, b.String(), "Procfile is valid: web, worker\nSidecarfile is valid: web\n", "output")
+
+	err = cmdr.BuildsValidate("web: \"\"\n", "web:\n- name: proxy\n  image: envoy:latest\n")
+	assert.Err(t, errors.New("found 1 problem(s):\n  Procfile: process type \"web\" has an empty command"), err)
+
+	err = cmdr.BuildsValidate("web", "")
+	assert.ExistsErr(t, err, "yaml")
+}
+
+func TestLoadBuildFiles(t *testing.T) {
+	// Create a new temporary directory and change to it.
+	name, err := ioutil.TempDir("", "client")
+	assert.NoErr(t, err)
+	assert.NoErr(t, os.Chdir(name))
+
+	procfile, sidecarfile, err := loadBuildFiles("", "")
+	assert.NoErr(t, err)
+	assert.Equal(t, procfile, map[string]string{}, "procfile")
+	assert.Equal(t, sidecarfile, map[string]interface{}{}, "sidecarfile")
+
+	assert.NoErr(t, ioutil.WriteFile("Procfile", []byte("web: ./server\n"), os.ModePerm))
+	procfile, _, err = loadBuildFiles("", "")
+	assert.NoErr(t, err)
+	assert.Equal(t, procfile, map[string]string{"web": "./server"}, "procfile")
+
+	procfile, _, err = loadBuildFiles("worker: ./work", "")
+	assert.NoErr(t, err)
+	assert.Equal(t, procfile, map[string]string{"worker": "./work"}, "procfile")
+}
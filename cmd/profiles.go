@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/deis/workflow-cli/settings"
+)
+
+// ProfilesList lists the profiles stored in ~/.deis.
+func (d *DeisCmd) ProfilesList() error {
+	profiles, err := settings.ListProfiles(d.ConfigFile)
+	if err != nil {
+		return err
+	}
+
+	if d.structured() {
+		return d.printStructured(profiles)
+	}
+
+	if len(profiles) == 0 {
+		d.Println("No profiles found. Use 'deis login' to create one.")
+		return nil
+	}
+
+	d.Println("=== Profiles")
+
+	w := new(tabwriter.Writer)
+
+	w.Init(d.WOut, 0, 8, 1, '\t', 0)
+	for _, p := range profiles {
+		current := " "
+		if p.Active {
+			current = "*"
+		}
+		fmt.Fprintf(w, "%s %s\t%s\t%s\n", current, p.Name, p.Controller, p.Username)
+	}
+	w.Flush()
+	return nil
+}
+
+// ProfilesUse sets the profile used when neither --config nor $DEIS_PROFILE is given.
+func (d *DeisCmd) ProfilesUse(name string) error {
+	if d.DryRun {
+		return settings.ErrReadOnly
+	}
+
+	if err := settings.UseProfile(name); err != nil {
+		return err
+	}
+
+	d.Printf("Now using profile %s\n", name)
+	return nil
+}
+
+// ProfilesShow prints the details of a profile, or of the active one if name is empty.
+func (d *DeisCmd) ProfilesShow(name string) error {
+	p, err := settings.GetProfile(d.ConfigFile, name)
+	if err != nil {
+		return err
+	}
+
+	if d.structured() {
+		return d.printStructured(p)
+	}
+
+	d.Printf("=== %s Profile\n", p.Name)
+	d.Println("controller: ", p.Controller)
+	d.Println("username:   ", p.Username)
+	d.Println("ssl_verify: ", p.VerifySSL)
+	d.Println("path:       ", p.Path)
+	d.Println("active:     ", p.Active)
+
+	return nil
+}
+
+// ProfilesRename renames a profile.
+func (d *DeisCmd) ProfilesRename(oldName, newName string) error {
+	if d.DryRun {
+		return settings.ErrReadOnly
+	}
+
+	if err := settings.RenameProfile(oldName, newName); err != nil {
+		return err
+	}
+
+	d.Printf("Renamed profile %s to %s\n", oldName, newName)
+	return nil
+}
+
+// ProfilesDelete removes a profile.
+func (d *DeisCmd) ProfilesDelete(name string) error {
+	if d.DryRun {
+		return settings.ErrReadOnly
+	}
+
+	if err := settings.DeleteProfile(name); err != nil {
+		return err
+	}
+
+	d.Printf("Deleted profile %s\n", name)
+	return nil
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arschles/assert"
+	"github.com/deis/workflow-cli/settings"
+)
+
+// The profile commands operate on ~/.deis, so these tests swap out $HOME and can't run in
+// parallel with the rest of the package.
+func setupProfilesHome(t *testing.T) (string, func()) {
+	home, err := ioutil.TempDir("", "deis-home")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(home, ".deis")
+	if err = os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	profiles := map[string]string{
+		"client.json":  `{"username":"admin","ssl_verify":true,"controller":"http://deis.staging.example.com","token":"a"}`,
+		"prod-us.json": `{"username":"ops","ssl_verify":false,"controller":"http://deis.prod-us.example.com","token":"b"}`,
+	}
+
+	for name, contents := range profiles {
+		if err = ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	oldHome := settings.FindHome()
+	settings.SetHome(home)
+
+	return dir, func() {
+		settings.SetHome(oldHome)
+		os.RemoveAll(home)
+	}
+}
+
+func TestProfilesList(t *testing.T) {
+	_, cleanup := setupProfilesHome(t)
+	defer cleanup()
+
+	var b bytes.Buffer
+	cmdr := DeisCmd{WOut: &b}
+
+	err := cmdr.ProfilesList()
+	assert.NoErr(t, err)
+	assert.Equal(t, b.String(), `=== Profiles
+* client	http://deis.staging.example.com	admin
+  prod-us	http://deis.prod-us.example.com	ops
+`, "output")
+}
+
+func TestProfilesUseAndShow(t *testing.T) {
+	dir, cleanup := setupProfilesHome(t)
+	defer cleanup()
+
+	var b bytes.Buffer
+	cmdr := DeisCmd{WOut: &b}
+
+	err := cmdr.ProfilesUse("prod-us")
+	assert.NoErr(t, err)
+	assert.Equal(t, b.String(), "Now using profile prod-us\n", "output")
+
+	b.Reset()
+	err = cmdr.ProfilesShow("")
+	assert.NoErr(t, err)
+	assert.Equal(t, b.String(), `=== prod-us Profile
+controller:  http://deis.prod-us.example.com
+username:    ops
+ssl_verify:  false
+path:        `+filepath.Join(dir, "prod-us.json")+`
+active:      true
+`, "output")
+}
+
+func TestProfilesRenameAndDelete(t *testing.T) {
+	_, cleanup := setupProfilesHome(t)
+	defer cleanup()
+
+	var b bytes.Buffer
+	cmdr := DeisCmd{WOut: &b, DryRun: true}
+
+	// The profiles are left alone on --dry-run.
+	assert.Err(t, settings.ErrReadOnly, cmdr.ProfilesRename("prod-us", "prod-eu"))
+	assert.Err(t, settings.ErrReadOnly, cmdr.ProfilesDelete("prod-us"))
+	assert.Err(t, settings.ErrReadOnly, cmdr.ProfilesUse("prod-us"))
+	assert.NoErr(t, cmdr.ProfilesShow("prod-us"))
+
+	b.Reset()
+	cmdr.DryRun = false
+	err := cmdr.ProfilesRename("prod-us", "prod-eu")
+	assert.NoErr(t, err)
+	assert.Equal(t, b.String(), "Renamed profile prod-us to prod-eu\n", "output")
+
+	b.Reset()
+	err = cmdr.ProfilesDelete("prod-eu")
+	assert.NoErr(t, err)
+	assert.Equal(t, b.String(), "Deleted profile prod-eu\n", "output")
+
+	err = cmdr.ProfilesShow("prod-eu")
+	assert.Err(t, errors.New("profile prod-eu not found"), err)
+}
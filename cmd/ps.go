@@ -4,17 +4,29 @@ import (
 	"fmt"
 	"io"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/deis/controller-sdk-go"
 	"github.com/deis/controller-sdk-go/api"
+	"github.com/deis/controller-sdk-go/apps"
 	"github.com/deis/controller-sdk-go/ps"
+	"github.com/olekukonko/tablewriter"
 )
 
-// PsList lists an app's processes.
-func (d *DeisCmd) PsList(appID string, results int) error {
+// psWatchInterval is how often processes are polled by ps:list --watch.
+var psWatchInterval = 2 * time.Second
+
+// clearScreen moves the cursor to the top left corner of the terminal and clears it.
+const clearScreen = "\033[H\033[2J"
+
+// PsList lists an app's processes, optionally only those of a type, in a state or on a
+// release. The age of processes is relative to now. If watch is true, the list is redrawn
+// whenever a process changes.
+func (d *DeisCmd) PsList(appID string, results int, psType, state, release string, watch bool,
+	now time.Time) error {
 	s, appID, err := load(d.ConfigFile, appID)
 	if err != nil {
 		return err
@@ -24,18 +36,115 @@ func (d *DeisCmd) PsList(appID string, results int) error {
 		results = s.Limit
 	}
 
-	processes, _, err := ps.List(s.Client, appID, results)
-	if d.checkAPICompatibility(s.Client, err) != nil {
-		return err
+	start := time.Now()
+	previous := ""
+
+	for {
+		processes, _, err := ps.List(s.Client, appID, results)
+		if d.checkAPICompatibility(s, err) != nil {
+			return err
+		}
+
+		processes = filterProcesses(processes, psType, state, release)
+
+		if d.structured() {
+			return d.printStructured(processes)
+		}
+
+		if !watch {
+			d.printProcessTable(appID, processes, now)
+			return nil
+		}
+
+		if current := processesKey(processes); current != previous {
+			d.Print(clearScreen)
+			d.printProcessTable(appID, processes, now.Add(time.Since(start)))
+			previous = current
+		}
+
+		time.Sleep(psWatchInterval)
 	}
+}
 
-	printProcesses(appID, processes, d.WOut)
+// filterProcesses returns the processes of a type, in a state and on a release. Empty
+// filters match every process.
+func filterProcesses(processes api.PodsList, psType, state, release string) api.PodsList {
+	if release != "" && !strings.HasPrefix(release, "v") {
+		release = "v" + release
+	}
 
-	return nil
+	filtered := api.PodsList{}
+	for _, process := range processes {
+		if (psType == "" || process.Type == psType) && (state == "" || process.State == state) &&
+			(release == "" || process.Release == release) {
+			filtered = append(filtered, process)
+		}
+	}
+
+	return filtered
+}
+
+// processesKey identifies the state of processes, to tell when it changes.
+func processesKey(processes api.PodsList) string {
+	var key []string
+	for _, process := range processes {
+		key = append(key, process.Name+" "+process.State+" "+process.Release)
+	}
+	sort.Strings(key)
+
+	return strings.Join(key, "\n")
 }
 
-// PsScale scales an app's processes.
-func (d *DeisCmd) PsScale(appID string, targets []string) error {
+// formatAge formats how long ago something started, such as 45s, 12m, 3h or 2d.
+func formatAge(age time.Duration) string {
+	switch {
+	case age < 0:
+		return "0s"
+	case age < time.Minute:
+		return fmt.Sprintf("%ds", int(age.Seconds()))
+	case age < time.Hour:
+		return fmt.Sprintf("%dm", int(age.Minutes()))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(age.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(age.Hours()/24))
+	}
+}
+
+// printProcessTable prints processes as a table, sorted by type and name.
+func (d *DeisCmd) printProcessTable(appID string, processes api.PodsList, now time.Time) {
+	d.Printf("=== %s Processes\n", appID)
+
+	if len(processes) == 0 {
+		d.Println("No processes found.")
+		return
+	}
+
+	table := tablewriter.NewWriter(d.WOut)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetBorder(false)
+	table.SetAutoFormatHeaders(false)
+	table.SetHeaderLine(true)
+	table.SetHeader([]string{"Type", "Name", "State", "Release", "Age", "Started"})
+
+	for _, process := range ps.ByType(processes) {
+		for _, pod := range process.PodsList {
+			age, started := "unknown", "unknown"
+			if pod.Started.Time != nil {
+				age = formatAge(now.Sub(*pod.Started.Time))
+				started = pod.Started.Time.UTC().Format(time.RFC3339)
+			}
+
+			table.Append([]string{pod.Type, pod.Name, pod.State, pod.Release, age, started})
+		}
+	}
+
+	table.Render()
+}
+
+// PsScale scales an app's processes. If wait isn't zero, it waits up to that long for the
+// scaled processes to be up.
+func (d *DeisCmd) PsScale(appID string, targets []string, wait time.Duration) error {
 	s, appID, err := load(d.ConfigFile, appID)
 	if err != nil {
 		return err
@@ -46,6 +155,22 @@ func (d *DeisCmd) PsScale(appID string, targets []string) error {
 		return err
 	}
 
+	if d.DryRun {
+		// The structure is what the app is scaled to, unlike the pods, which may be crashing,
+		// terminating or not scheduled yet.
+		app, err := apps.Get(s.Client, appID)
+		if d.checkAPICompatibility(s, err) != nil {
+			return err
+		}
+
+		changes, err := payloadChanges(targetMap, app.Structure)
+		if err != nil {
+			return err
+		}
+
+		return d.dryRun(appID, "scale processes", targetMap, changes)
+	}
+
 	d.Printf("Scaling processes... but first, %s!\n", drinkOfChoice())
 	startTime := time.Now()
 	quit := progress(d.WOut)
@@ -53,12 +178,21 @@ func (d *DeisCmd) PsScale(appID string, targets []string) error {
 	err = ps.Scale(s.Client, appID, targetMap)
 	quit <- true
 	<-quit
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
 	d.Printf("done in %ds\n", int(time.Since(startTime).Seconds()))
 
+	if wait > 0 {
+		types := make([]string, 0, len(targetMap))
+		for psType := range targetMap {
+			types = append(types, psType)
+		}
+
+		return d.waitForLatestRelease(s, appID, types, wait)
+	}
+
 	processes, _, err := ps.List(s.Client, appID, s.Limit)
 	if err != nil {
 		return err
@@ -68,8 +202,9 @@ func (d *DeisCmd) PsScale(appID string, targets []string) error {
 	return nil
 }
 
-// PsRestart restarts an app's processes.
-func (d *DeisCmd) PsRestart(appID, target string) error {
+// PsRestart restarts an app's processes. If wait isn't zero, it waits up to that long for
+// the restarted processes to be up.
+func (d *DeisCmd) PsRestart(appID, target string, wait time.Duration) error {
 	s, appID, err := load(d.ConfigFile, appID)
 	if err != nil {
 		return err
@@ -89,20 +224,41 @@ func (d *DeisCmd) PsRestart(appID, target string) error {
 	<-quit
 	if err == deis.ErrPodNotFound {
 		return fmt.Errorf("Could not find process type %s in app %s", psType, appID)
-	} else if d.checkAPICompatibility(s.Client, err) != nil {
+	} else if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
 	if len(processes) == 0 {
 		d.Println("Could not find any processes to restart")
-	} else {
-		d.Printf("done in %ds\n", int(time.Since(startTime).Seconds()))
-		printProcesses(appID, processes, d.WOut)
+		return nil
+	}
+
+	d.Printf("done in %ds\n", int(time.Since(startTime).Seconds()))
+
+	if wait > 0 {
+		var types []string
+		if psType != "" {
+			types = []string{psType}
+		}
+
+		return d.waitForLatestRelease(s, appID, types, wait)
 	}
 
+	printProcesses(appID, processes, d.WOut)
 	return nil
 }
 
+// PsWait waits until every process of the given types, or of all types if there are none,
+// is up on the app's latest release.
+func (d *DeisCmd) PsWait(appID string, types []string, timeout time.Duration) error {
+	s, appID, err := load(d.ConfigFile, appID)
+	if err != nil {
+		return err
+	}
+
+	return d.waitForLatestRelease(s, appID, types, timeout)
+}
+
 func printProcesses(appID string, input []api.Pods, wOut io.Writer) {
 	processes := ps.ByType(input)
 
@@ -142,7 +298,7 @@ func parseType(target string, appID string) (string, string) {
 
 func parsePsTargets(targets []string) (map[string]int, error) {
 	targetMap := make(map[string]int)
-	regex := regexp.MustCompile(`^([a-z0-9]+(?:-[a-z0-9]+)*)=([0-9]+)$`)
+	regex := regexp.MustCompile(`^(` + processTypePattern + `)=([0-9]+)$`)
 	var err error
 
 	for _, target := range targets {
@@ -4,11 +4,13 @@ import (
 	"bytes"
 	"fmt"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/arschles/assert"
 	"github.com/deis/controller-sdk-go/api"
-	"github.com/deis/controller-sdk-go/pkg/time"
+	dtime "github.com/deis/controller-sdk-go/pkg/time"
 	"github.com/deis/workflow-cli/pkg/testutil"
 )
 
@@ -47,14 +49,14 @@ func TestPrintProcesses(t *testing.T) {
 			Name:    "benign-quilting-web-4084101150-c871y",
 			Type:    "web",
 			State:   "up",
-			Started: time.Time{},
+			Started: dtime.Time{},
 		},
 		{
 			Release: "v3",
 			Name:    "benign-quilting-worker-4084101150-c871y",
 			Type:    "worker",
 			State:   "up",
-			Started: time.Time{},
+			Started: dtime.Time{},
 		},
 	}
 
@@ -81,7 +83,7 @@ func TestPsList(t *testing.T) {
 	server.Mux.HandleFunc("/v2/apps/foo/pods/", func(w http.ResponseWriter, r *http.Request) {
 		testutil.SetHeaders(w)
 		fmt.Fprintf(w, `{
-			"count": 1,
+			"count": 3,
 			"next": null,
 			"previous": null,
 			"results": [
@@ -91,18 +93,82 @@ func TestPsList(t *testing.T) {
 					"name": "foo-web-4084101150-c871y",
 					"state": "up",
 					"started": "2016-02-13T00:47:52"
+				},
+				{
+					"release": "v3",
+					"type": "worker",
+					"name": "foo-worker-4084101150-a2b4c",
+					"state": "crashed",
+					"started": "2016-02-15T09:30:00"
+				},
+				{
+					"release": "v3",
+					"type": "web",
+					"name": "foo-web-4084101150-x9z8y",
+					"state": "starting",
+					"started": null
 				}
 			]
 		}`)
 	})
 
-	err = cmdr.PsList("foo", -1)
+	now := time.Date(2016, 2, 15, 10, 0, 0, 0, time.UTC)
+
+	err = cmdr.PsList("foo", -1, "", "", "", false, now)
 	assert.NoErr(t, err)
 
 	assert.Equal(t, b.String(), `=== foo Processes
---- web:
-foo-web-4084101150-c871y up (v2)
+   Type  |            Name             |  State   | Release |   Age   |       Started         
++--------+-----------------------------+----------+---------+---------+----------------------+
+  web    | foo-web-4084101150-c871y    | up       | v2      | 2d      | 2016-02-13T00:47:52Z  
+  web    | foo-web-4084101150-x9z8y    | starting | v3      | unknown | unknown               
+  worker | foo-worker-4084101150-a2b4c | crashed  | v3      | 30m     | 2016-02-15T09:30:00Z  
 `, "output")
+
+	b.Reset()
+	err = cmdr.PsList("foo", -1, "web", "", "3", false, now)
+	assert.NoErr(t, err)
+	assert.Equal(t, strings.Contains(b.String(), "foo-web-4084101150-x9z8y"), true, "v3 web process")
+	assert.Equal(t, strings.Count(b.String(), "foo-"), 1, "processes")
+
+	b.Reset()
+	err = cmdr.PsList("foo", -1, "", "running", "", false, now)
+	assert.NoErr(t, err)
+	assert.Equal(t, b.String(), "=== foo Processes\nNo processes found.\n", "output")
+}
+
+func TestPsListWatch(t *testing.T) {
+	psWatchInterval = time.Millisecond
+	defer func() { psWatchInterval = 2 * time.Second }()
+
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	var b bytes.Buffer
+	cmdr := DeisCmd{WOut: &b, ConfigFile: cf}
+
+	states := []string{"starting", "starting", "up"}
+	polls := 0
+	server.Mux.HandleFunc("/v2/apps/foo/pods/", func(w http.ResponseWriter, r *http.Request) {
+		if polls == len(states) {
+			// stop watching
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"count": 1, "results": [{"release": "v2", "type": "web",
+			"name": "foo-web-4084101150-c871y", "state": "%s", "started": null}]}`, states[polls])
+		polls++
+	})
+
+	err = cmdr.PsList("foo", -1, "", "", "", true, time.Now())
+	assert.ExistsErr(t, err, "server error")
+	assert.Equal(t, strings.Count(b.String(), clearScreen), 2, "redraws")
+	assert.Equal(t, strings.Contains(b.String(), "| starting |"), true, "first state")
+	assert.Equal(t, strings.Contains(b.String(), "| up "), true, "second state")
 }
 
 type psTargetCases struct {
@@ -145,7 +211,7 @@ func TestPsScale(t *testing.T) {
 	defer server.Close()
 	var b bytes.Buffer
 	cmdr := DeisCmd{WOut: &b, ConfigFile: cf}
-	err = cmdr.PsScale("foo", []string{"test"})
+	err = cmdr.PsScale("foo", []string{"test"}, 0)
 	assert.Equal(t, err.Error(), "'test' does not match the pattern 'type=num', ex: web=2\n", "error")
 
 	server.Mux.HandleFunc("/v2/apps/foo/pods/", func(w http.ResponseWriter, r *http.Request) {
@@ -173,7 +239,7 @@ func TestPsScale(t *testing.T) {
 	})
 
 	b.Reset()
-	err = cmdr.PsScale("foo", []string{"web=1"})
+	err = cmdr.PsScale("foo", []string{"web=1"}, 0)
 	assert.NoErr(t, err)
 	assert.Equal(t, testutil.StripProgress(b.String()), `Scaling processes... but first, coffee!
 done in 0s
@@ -207,7 +273,7 @@ func TestPsRestart(t *testing.T) {
 	})
 
 	b.Reset()
-	err = cmdr.PsRestart("foo", "")
+	err = cmdr.PsRestart("foo", "", 0)
 	assert.NoErr(t, err)
 	assert.Equal(t, testutil.StripProgress(b.String()), `Restarting processes... but first, coffee!
 done in 0s
@@ -223,7 +289,7 @@ foo-web-4084101150-c871y up (v2)
 
 	b.Reset()
 
-	err = cmdr.PsRestart("coolapp", "")
+	err = cmdr.PsRestart("coolapp", "", 0)
 	assert.NoErr(t, err)
 	assert.Equal(t, testutil.StripProgress(b.String()), `Restarting processes... but first, coffee!
 Could not find any processes to restart
@@ -244,7 +310,7 @@ Could not find any processes to restart
 
 	b.Reset()
 
-	err = cmdr.PsRestart("testapp", "web")
+	err = cmdr.PsRestart("testapp", "web", 0)
 	assert.NoErr(t, err)
 	assert.Equal(t, testutil.StripProgress(b.String()), `Restarting processes... but first, coffee!
 done in 0s
@@ -268,7 +334,7 @@ testapp-web-4084101150-c871y up (v2)
 
 	b.Reset()
 
-	err = cmdr.PsRestart("newapp", "newapp-web-4084101150-c871y")
+	err = cmdr.PsRestart("newapp", "newapp-web-4084101150-c871y", 0)
 	assert.NoErr(t, err)
 	assert.Equal(t, testutil.StripProgress(b.String()), `Restarting processes... but first, coffee!
 done in 0s
@@ -287,6 +353,6 @@ newapp-web-4084101150-c871y up (v2)
 
 	b.Reset()
 
-	err = cmdr.PsRestart("newapp", "ghost")
+	err = cmdr.PsRestart("newapp", "ghost", 0)
 	assert.Equal(t, err.Error(), "Could not find process type ghost in app newapp", "error")
 }
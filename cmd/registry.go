@@ -19,10 +19,14 @@ func (d *DeisCmd) RegistryList(appID string) error {
 	}
 
 	config, err := config.List(s.Client, appID)
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
+	if d.structured() {
+		return d.printStructured(api.Config{Registry: config.Registry})
+	}
+
 	d.Printf("=== %s Registry\n", appID)
 
 	registryMap := make(map[string]string)
@@ -49,16 +53,21 @@ func (d *DeisCmd) RegistrySet(appID string, item []string) error {
 		return err
 	}
 
+	configObj := api.Config{}
+	configObj.Registry = registryMap
+
+	if d.DryRun {
+		return d.dryRunConfig(s, appID, configObj, false)
+	}
+
 	d.Print("Applying registry information... ")
 
 	quit := progress(d.WOut)
-	configObj := api.Config{}
-	configObj.Registry = registryMap
 
 	_, err = config.Set(s.Client, appID, configObj)
 	quit <- true
 	<-quit
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
@@ -75,10 +84,6 @@ func (d *DeisCmd) RegistryUnset(appID string, items []string) error {
 		return err
 	}
 
-	d.Print("Applying registry information... ")
-
-	quit := progress(d.WOut)
-
 	configObj := api.Config{}
 
 	registryMap := make(map[string]interface{})
@@ -89,10 +94,18 @@ func (d *DeisCmd) RegistryUnset(appID string, items []string) error {
 
 	configObj.Registry = registryMap
 
+	if d.DryRun {
+		return d.dryRunConfig(s, appID, configObj, false)
+	}
+
+	d.Print("Applying registry information... ")
+
+	quit := progress(d.WOut)
+
 	_, err = config.Set(s.Client, appID, configObj)
 	quit <- true
 	<-quit
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
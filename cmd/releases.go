@@ -2,13 +2,22 @@ package cmd
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"text/tabwriter"
+	"time"
 
+	"github.com/deis/controller-sdk-go/api"
+	"github.com/deis/controller-sdk-go/config"
 	"github.com/deis/controller-sdk-go/releases"
+	"github.com/deis/workflow-cli/settings"
 )
 
-// ReleasesList lists an app's releases.
-func (d *DeisCmd) ReleasesList(appID string, results int) error {
+// ReleasesList lists an app's releases, newest first. Only the releases by owner, whose
+// summary contains summary, created between since and until, and after sinceVersion are
+// listed. Empty filters match every release.
+func (d *DeisCmd) ReleasesList(appID string, results int, owner, summary string, since,
+	until time.Time, sinceVersion int) error {
 	s, appID, err := load(d.ConfigFile, appID)
 
 	if err != nil {
@@ -19,23 +28,89 @@ func (d *DeisCmd) ReleasesList(appID string, results int) error {
 		results = s.Limit
 	}
 
-	releases, count, err := releases.List(s.Client, appID, results)
-	if d.checkAPICompatibility(s.Client, err) != nil {
-		return err
+	var appReleases []api.Release
+	var count int
+
+	if owner == "" && summary == "" && since.IsZero() && until.IsZero() && sinceVersion <= 0 {
+		appReleases, count, err = releases.List(s.Client, appID, results)
+		if d.checkAPICompatibility(s, err) != nil {
+			return err
+		}
+	} else {
+		// The controller can't filter releases, so every release is fetched and filtered here.
+		if appReleases, err = d.allReleases(s, appID); err != nil {
+			return err
+		}
+
+		appReleases = filterReleases(appReleases, owner, summary, since, until, sinceVersion)
+		count = len(appReleases)
+		if len(appReleases) > results {
+			appReleases = appReleases[:results]
+		}
 	}
 
-	d.Printf("=== %s Releases%s", appID, limitCount(len(releases), count))
+	if d.structured() {
+		return d.printStructured(appReleases)
+	}
+
+	d.Printf("=== %s Releases%s", appID, limitCount(len(appReleases), count))
 
 	w := new(tabwriter.Writer)
 
 	w.Init(d.WOut, 0, 8, 1, '\t', 0)
-	for _, r := range releases {
+	for _, r := range appReleases {
 		fmt.Fprintf(w, "v%d\t%s\t%s\n", r.Version, r.Created, r.Summary)
 	}
 	w.Flush()
 	return nil
 }
 
+// allReleases returns every release of an app, newest first.
+func (d *DeisCmd) allReleases(s *settings.Settings, appID string) ([]api.Release, error) {
+	appReleases, count, err := releases.List(s.Client, appID, s.Limit)
+	if d.checkAPICompatibility(s, err) != nil {
+		return nil, err
+	}
+
+	if count > len(appReleases) {
+		appReleases, _, err = releases.List(s.Client, appID, count)
+		if d.checkAPICompatibility(s, err) != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(appReleases, func(i, j int) bool { return appReleases[i].Version > appReleases[j].Version })
+
+	return appReleases, nil
+}
+
+// filterReleases returns the releases by owner, whose summary contains summary regardless of
+// case, created between since and until, and after sinceVersion. Empty filters match every
+// release.
+func filterReleases(appReleases []api.Release, owner, summary string, since, until time.Time,
+	sinceVersion int) []api.Release {
+	summary = strings.ToLower(summary)
+	filtered := []api.Release{}
+
+	for _, release := range appReleases {
+		if (owner != "" && release.Owner != owner) || release.Version <= sinceVersion ||
+			!strings.Contains(strings.ToLower(release.Summary), summary) {
+			continue
+		}
+
+		if !since.IsZero() || !until.IsZero() {
+			created, ok := parseTimestamp(release.Created)
+			if !ok || (!since.IsZero() && created.Before(since)) || (!until.IsZero() && created.After(until)) {
+				continue
+			}
+		}
+
+		filtered = append(filtered, release)
+	}
+
+	return filtered
+}
+
 // ReleasesInfo prints info about a specific release.
 func (d *DeisCmd) ReleasesInfo(appID string, version int) error {
 	s, appID, err := load(d.ConfigFile, appID)
@@ -45,10 +120,14 @@ func (d *DeisCmd) ReleasesInfo(appID string, version int) error {
 	}
 
 	r, err := releases.Get(s.Client, appID, version)
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
+	if d.structured() {
+		return d.printStructured(r)
+	}
+
 	d.Printf("=== %s Release v%d\n", appID, version)
 	if r.Build != "" {
 		d.Println("build:   ", r.Build)
@@ -63,14 +142,73 @@ func (d *DeisCmd) ReleasesInfo(appID string, version int) error {
 	return nil
 }
 
-// ReleasesRollback rolls an app back to a previous release.
-func (d *DeisCmd) ReleasesRollback(appID string, version int) error {
+// ReleasesRollback rolls an app back to a previous release, or to the one before the current
+// release if version is -1. It first shows what differs from the current release, and asks
+// to type the app's name to confirm unless it matches confirm or yes is true. If wait isn't
+// zero, it waits up to that long for the processes to be up on the new release.
+func (d *DeisCmd) ReleasesRollback(appID string, version int, wait time.Duration, confirm string,
+	yes bool) error {
 	s, appID, err := load(d.ConfigFile, appID)
 
 	if err != nil {
 		return err
 	}
 
+	latest, err := d.latestRelease(s, appID)
+	if err != nil {
+		return err
+	}
+
+	target := version
+	if target == -1 {
+		target = latest - 1
+	}
+	if target < 1 {
+		return fmt.Errorf("%s has no release before v%d to roll back to", appID, latest)
+	}
+
+	current, err := releases.Get(s.Client, appID, latest)
+	if d.checkAPICompatibility(s, err) != nil {
+		return err
+	}
+
+	restored, err := releases.Get(s.Client, appID, target)
+	if d.checkAPICompatibility(s, err) != nil {
+		return err
+	}
+
+	diff, err := d.diffReleases(s, appID, current, restored)
+	if err != nil {
+		return err
+	}
+
+	d.Printf("=== %s Rollback from v%d to v%d\n", appID, latest, target)
+	d.Printf("Restores v%d (%s): %s\n", restored.Version, restored.Created, restored.Summary)
+	if err = d.printReleaseDiff(diff); err != nil {
+		return err
+	}
+
+	// The diff is the preview, so there's nothing to confirm.
+	if d.DryRun {
+		d.Println()
+		d.Println("Nothing was sent to the controller, as --dry-run was given.")
+		return nil
+	}
+
+	if !yes && confirm == "" {
+		d.Printf(` !    WARNING: Potentially Destructive Action
+ !    This command will roll back the application %s to v%d.
+ !    To proceed, type "%s" or re-run this command with --confirm=%s or --yes
+
+> `, appID, target, appID, appID)
+
+		fmt.Fscanln(d.WIn, &confirm)
+	}
+
+	if !yes && confirm != appID {
+		return fmt.Errorf("App %s does not match confirm %s, aborting.", appID, confirm)
+	}
+
 	if version == -1 {
 		d.Print("Rolling back one release... ")
 	} else {
@@ -81,11 +219,209 @@ func (d *DeisCmd) ReleasesRollback(appID string, version int) error {
 	newVersion, err := releases.Rollback(s.Client, appID, version)
 	quit <- true
 	<-quit
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
 	d.Printf("done, v%d\n", newVersion)
 
+	if wait > 0 {
+		return d.waitForProcesses(s, appID, newVersion, nil, wait)
+	}
+
+	return nil
+}
+
+// releaseDiff holds what changed between two releases. The controller only serves an app's
+// current config, so config changes are the releases that made them, along with the values
+// of the current config when one of the releases uses it. The values of older configs can't
+// be shown.
+type releaseDiff struct {
+	From   int              `json:"from"`
+	To     int              `json:"to"`
+	Build  []manifestChange `json:"build"`
+	Config []api.Release    `json:"config"`
+	// Current is the config of the release CurrentVersion, with secrets masked.
+	Current        *api.Config `json:"current_config,omitempty"`
+	CurrentVersion int         `json:"current_version,omitempty"`
+
+	fromBuild map[string]string
+	toBuild   map[string]string
+}
+
+// ReleasesDiff prints what changed between two releases of an app: a unified diff of their
+// builds, the releases between them that changed the config, and the values of the config if
+// one of them uses the current one.
+func (d *DeisCmd) ReleasesDiff(appID string, from, to int) error {
+	s, appID, err := load(d.ConfigFile, appID)
+
+	if err != nil {
+		return err
+	}
+
+	fromRelease, err := releases.Get(s.Client, appID, from)
+	if d.checkAPICompatibility(s, err) != nil {
+		return err
+	}
+
+	toRelease, err := releases.Get(s.Client, appID, to)
+	if d.checkAPICompatibility(s, err) != nil {
+		return err
+	}
+
+	diff, err := d.diffReleases(s, appID, fromRelease, toRelease)
+	if err != nil {
+		return err
+	}
+
+	if d.structured() {
+		if err = d.printStructured(diff); err != nil {
+			return err
+		}
+	} else {
+		d.Printf("=== %s Release Diff v%d v%d\n", appID, from, to)
+		return d.printReleaseDiff(diff)
+	}
+
+	return nil
+}
+
+// diffReleases resolves the builds of two releases and the releases between them that
+// changed the config.
+func (d *DeisCmd) diffReleases(s *settings.Settings, appID string, from, to api.Release) (releaseDiff, error) {
+	diff := releaseDiff{From: from.Version, To: to.Version, Build: []manifestChange{}, Config: []api.Release{}}
+
+	if from.Build != to.Build {
+		appBuilds, err := d.appBuilds(s, appID)
+		if err != nil {
+			return releaseDiff{}, err
+		}
+
+		diff.fromBuild = renderBuild(appBuilds[from.Build])
+		diff.toBuild = renderBuild(appBuilds[to.Build])
+		if changes := diffFlat("build", diff.toBuild, diff.fromBuild, func(string, bool) {}); len(changes) > 0 {
+			diff.Build = changes
+		}
+	}
+
+	if from.Config != to.Config {
+		first, last := from.Version, to.Version
+		if first > last {
+			first, last = last, first
+		}
+
+		changed, err := d.releasesChangingConfig(s, appID, first)
+		if err != nil {
+			return releaseDiff{}, err
+		}
+
+		for _, release := range changed {
+			if release.Version <= last {
+				diff.Config = append(diff.Config, release)
+			}
+		}
+
+		current, err := config.List(s.Client, appID)
+		if d.checkAPICompatibility(s, err) != nil {
+			return releaseDiff{}, err
+		}
+
+		for _, release := range []api.Release{from, to} {
+			if release.Config == current.UUID {
+				current.Values = maskSecrets(current.Values, secretPatterns(s))
+				diff.Current, diff.CurrentVersion = &current, release.Version
+			}
+		}
+	}
+
+	return diff, nil
+}
+
+// printReleaseDiff prints the builds of a diff as a unified diff, followed by the releases
+// that changed the config and the current config.
+func (d *DeisCmd) printReleaseDiff(diff releaseDiff) error {
+	if len(diff.Build) == 0 {
+		d.Println("The build is the same.")
+	} else {
+		d.Printf("--- v%d/build\n+++ v%d/build\n", diff.From, diff.To)
+		for _, line := range unifiedDiff(diff.fromBuild, diff.toBuild) {
+			d.Println(line)
+		}
+	}
+
+	if len(diff.Config) == 0 {
+		d.Println("The config is the same.")
+		return nil
+	}
+
+	d.Printf("The config was changed by %d release(s) between v%d and v%d:\n", len(diff.Config),
+		diff.From, diff.To)
+
+	w := new(tabwriter.Writer)
+	w.Init(d.WOut, 0, 8, 1, '\t', 0)
+	for _, r := range diff.Config {
+		fmt.Fprintf(w, "v%d\t%s\t%s\n", r.Version, r.Created, r.Summary)
+	}
+	w.Flush()
+
+	if diff.Current == nil {
+		d.Println("Only the current config is kept, so the values can't be compared.")
+		return nil
+	}
+
+	// Listing the current config as changes from an empty one renders each of its settings.
+	changes, err := payloadChanges(*diff.Current, api.Config{})
+	if err != nil {
+		return err
+	}
+
+	d.Printf("The config of v%d is the current one:\n", diff.CurrentVersion)
+	for _, change := range changes {
+		if _, ok := payloadDepth[change.Section]; ok && change.New != "" {
+			d.Println(strings.TrimPrefix(change.String(), "+ "))
+		}
+	}
+
 	return nil
 }
+
+// renderBuild renders the image, process types and sidecars of a build as lines to compare.
+func renderBuild(build api.Build) map[string]string {
+	rendered := make(map[string]string)
+	if build.Image != "" {
+		rendered["image"] = build.Image
+	}
+	for procType, command := range build.Procfile {
+		rendered["procfile "+procType] = command
+	}
+	for procType, sidecars := range build.Sidecarfile {
+		rendered["sidecarfile "+procType] = jsonValue(sidecars)
+	}
+
+	return rendered
+}
+
+// unifiedDiff compares rendered lines by key, prefixing removed lines with -, added lines
+// with + and unchanged lines with a space.
+func unifiedDiff(before, after map[string]string) []string {
+	var lines []string
+
+	for _, key := range sortedKeys(before, after) {
+		oldValue, inOld := before[key]
+		newValue, inNew := after[key]
+
+		switch {
+		case inOld && inNew && oldValue == newValue:
+			lines = append(lines, fmt.Sprintf(" %s: %s", key, oldValue))
+		default:
+			if inOld {
+				lines = append(lines, fmt.Sprintf("-%s: %s", key, oldValue))
+			}
+			if inNew {
+				lines = append(lines, fmt.Sprintf("+%s: %s", key, newValue))
+			}
+		}
+	}
+
+	return lines
+}
@@ -2,10 +2,14 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/arschles/assert"
 	"github.com/deis/controller-sdk-go/api"
@@ -55,7 +59,7 @@ func TestReleasesList(t *testing.T) {
 		}`)
 	})
 
-	err = cmdr.ReleasesList("numenor", -1)
+	err = cmdr.ReleasesList("numenor", -1, "", "", time.Time{}, time.Time{}, 0)
 	assert.NoErr(t, err)
 	assert.Equal(t, b.String(), `=== numenor Releases
 v2	2016-08-22T17:40:16Z	khamul added ANGMAR
@@ -95,13 +99,53 @@ func TestReleasesListLimit(t *testing.T) {
 		}`)
 	})
 
-	err = cmdr.ReleasesList("numenor", 1)
+	err = cmdr.ReleasesList("numenor", 1, "", "", time.Time{}, time.Time{}, 0)
 	assert.NoErr(t, err)
 	assert.Equal(t, b.String(), `=== numenor Releases (1 of 2)
 v2	2016-08-22T17:40:16Z	khamul added ANGMAR
 `, "output")
 }
 
+func TestReleasesListFilters(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	handleReleases(t, server, "foo",
+		api.Release{Version: 15, Owner: "admin", Created: "2017-03-17T09:00:00UTC", Summary: "admin changed limits for web"},
+		api.Release{Version: 14, Owner: "jane", Created: "2017-03-14T10:00:00UTC", Summary: "jane deployed foo:v2"},
+		api.Release{Version: 13, Owner: "admin", Created: "2017-03-10T16:00:00UTC", Summary: "admin added FOO"},
+		api.Release{Version: 12, Owner: "admin", Created: "2017-03-01T12:00:00UTC", Summary: "admin deployed foo:v1"})
+
+	var b bytes.Buffer
+	cmdr := DeisCmd{WOut: &b, ConfigFile: cf}
+
+	err = cmdr.ReleasesList("foo", -1, "admin", "", time.Date(2017, 3, 9, 0, 0, 0, 0, time.UTC), time.Time{}, 0)
+	assert.NoErr(t, err)
+	assert.Equal(t, b.String(), `=== foo Releases
+v15	2017-03-17T09:00:00UTC	admin changed limits for web
+v13	2017-03-10T16:00:00UTC	admin added FOO
+`, "output")
+
+	b.Reset()
+	err = cmdr.ReleasesList("foo", 1, "", "DEPLOYED", time.Time{}, time.Time{}, 0)
+	assert.NoErr(t, err)
+	assert.Equal(t, b.String(), `=== foo Releases (1 of 2)
+v14	2017-03-14T10:00:00UTC	jane deployed foo:v2
+`, "output")
+
+	b.Reset()
+	err = cmdr.ReleasesList("foo", -1, "", "", time.Time{}, time.Date(2017, 3, 15, 0, 0, 0, 0, time.UTC), 12)
+	assert.NoErr(t, err)
+	assert.Equal(t, b.String(), `=== foo Releases
+v14	2017-03-14T10:00:00UTC	jane deployed foo:v2
+v13	2017-03-10T16:00:00UTC	admin added FOO
+`, "output")
+}
+
 func TestReleasesInfo(t *testing.T) {
 	t.Parallel()
 	cf, server, err := testutil.NewTestServerAndClient()
@@ -139,6 +183,34 @@ uuid:     c4aed81c-d1ca-4ff1-ab89-d2151264e1a3
 `, "output")
 }
 
+// handleReleases serves the list and details of an app's releases, given newest first.
+func handleReleases(t *testing.T, server *testutil.TestServer, appID string, history ...api.Release) {
+	prefix := fmt.Sprintf("/v2/apps/%s/releases/", appID)
+
+	server.Mux.HandleFunc(prefix, func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+
+		version := strings.Trim(strings.TrimPrefix(r.URL.Path, prefix), "/")
+		if version == "" {
+			results, err := json.Marshal(history)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fmt.Fprintf(w, `{"count": %d, "results": %s}`, len(history), results)
+			return
+		}
+
+		for _, release := range history {
+			if fmt.Sprintf("v%d", release.Version) == version {
+				json.NewEncoder(w).Encode(release)
+				return
+			}
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	})
+}
+
 func TestReleasesRollback(t *testing.T) {
 	t.Parallel()
 	cf, server, err := testutil.NewTestServerAndClient()
@@ -149,6 +221,15 @@ func TestReleasesRollback(t *testing.T) {
 	var b bytes.Buffer
 	cmdr := DeisCmd{WOut: &b, ConfigFile: cf}
 
+	handleReleases(t, server, "numenor",
+		api.Release{Version: 4, Build: "b1", Config: "c2", Created: "2017-03-02T00:00:00UTC", Summary: "admin added FOO"},
+		api.Release{Version: 3, Build: "b1", Config: "c1", Created: "2017-03-01T00:00:00UTC", Summary: "admin deployed foo:v1"})
+
+	server.Mux.HandleFunc("/v2/apps/numenor/config/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprint(w, `{"uuid": "c2", "values": {"FOO": "bar"}}`)
+	})
+
 	server.Mux.HandleFunc("/v2/apps/numenor/releases/rollback/", func(w http.ResponseWriter, r *http.Request) {
 		testutil.SetHeaders(w)
 		body, err := ioutil.ReadAll(r.Body)
@@ -161,20 +242,198 @@ func TestReleasesRollback(t *testing.T) {
 		fmt.Fprintf(w, `{"version": 5}`)
 	})
 
-	err = cmdr.ReleasesRollback("numenor", -1)
+	err = cmdr.ReleasesRollback("numenor", -1, 0, "", true)
 	assert.NoErr(t, err)
-	assert.Equal(t, testutil.StripProgress(b.String()), "Rolling back one release... done, v5\n", "output")
+	assert.Equal(t, testutil.StripProgress(b.String()), `=== numenor Rollback from v4 to v3
+Restores v3 (2017-03-01T00:00:00UTC): admin deployed foo:v1
+The build is the same.
+The config was changed by 1 release(s) between v4 and v3:
+v4	2017-03-02T00:00:00UTC	admin added FOO
+The config of v4 is the current one:
+values FOO=bar
+Rolling back one release... done, v5
+`, "output")
+
+	handleReleases(t, server, "angmar",
+		api.Release{Version: 4, Build: "b1", Config: "c1"},
+		api.Release{Version: 3, Build: "b1", Config: "c1"})
 
 	server.Mux.HandleFunc("/v2/apps/angmar/releases/rollback/", func(w http.ResponseWriter, r *http.Request) {
 		testutil.SetHeaders(w)
 		testutil.AssertBody(t, api.ReleaseRollback{Version: 3}, r)
 		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"version": 5}`)
+	})
+
+	b.Reset()
+
+	err = cmdr.ReleasesRollback("angmar", 3, 0, "angmar", false)
+	assert.NoErr(t, err)
+	assert.Equal(t, strings.HasSuffix(testutil.StripProgress(b.String()), "Rolling back to v3... done, v5\n"),
+		true, "output")
+}
+
+func TestReleasesRollbackConfirm(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	handleReleases(t, server, "foo",
+		api.Release{Version: 2, Build: "b1", Config: "c1"},
+		api.Release{Version: 1, Build: "b1", Config: "c1"})
+
+	rolledBack := false
+	server.Mux.HandleFunc("/v2/apps/foo/releases/rollback/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		rolledBack = true
+		w.WriteHeader(http.StatusCreated)
 		fmt.Fprintf(w, `{"version": 3}`)
 	})
 
+	var b bytes.Buffer
+	cmdr := DeisCmd{WOut: &b, WIn: strings.NewReader("bar\n"), ConfigFile: cf}
+
+	err = cmdr.ReleasesRollback("foo", -1, 0, "", false)
+	assert.Err(t, errors.New("App foo does not match confirm bar, aborting."), err)
+	assert.Equal(t, rolledBack, false, "rolled back")
+	assert.Equal(t, strings.Contains(b.String(), `To proceed, type "foo"`), true, "prompt")
+
+	cmdr.WIn = strings.NewReader("foo\n")
+	err = cmdr.ReleasesRollback("foo", -1, 0, "", false)
+	assert.NoErr(t, err)
+	assert.Equal(t, rolledBack, true, "rolled back")
+
+	handleReleases(t, server, "bar", api.Release{Version: 1, Build: "b1", Config: "c1"})
+	err = cmdr.ReleasesRollback("bar", -1, 0, "", true)
+	assert.Err(t, errors.New("bar has no release before v1 to roll back to"), err)
+}
+
+func TestReleasesRollbackDryRun(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	handleReleases(t, server, "foo",
+		api.Release{Version: 2, Build: "b2", Config: "c1"},
+		api.Release{Version: 1, Build: "b1", Config: "c1"})
+
+	server.Mux.HandleFunc("/v2/apps/foo/builds/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprint(w, `{"count": 2, "results": [
+			{"uuid": "b2", "image": "foo:v2", "procfile": {}, "sidecarfile": {}},
+			{"uuid": "b1", "image": "foo:v1", "procfile": {}, "sidecarfile": {}}
+		]}`)
+	})
+
+	server.Mux.HandleFunc("/v2/apps/foo/releases/rollback/", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected %s request", r.Method)
+	})
+
+	var b bytes.Buffer
+	// Nothing is read, as there's nothing to confirm.
+	cmdr := DeisCmd{WOut: &b, WIn: strings.NewReader("foo\n"), ConfigFile: cf, DryRun: true}
+
+	err = cmdr.ReleasesRollback("foo", -1, 0, "", false)
+	assert.NoErr(t, err)
+	assert.Equal(t, b.String(), `=== foo Rollback from v2 to v1
+Restores v1 (): 
+--- v2/build
++++ v1/build
+-image: foo:v2
++image: foo:v1
+The config is the same.
+
+Nothing was sent to the controller, as --dry-run was given.
+`, "output")
+}
+
+func TestReleasesDiff(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	server.Mux.HandleFunc("/v2/apps/foo/releases/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+
+		history := map[string]string{
+			"v12": `{"version": 12, "build": "b1", "config": "c1", "created": "2017-03-01T00:00:00UTC", "summary": "admin deployed foo:v1"}`,
+			"v13": `{"version": 13, "build": "b1", "config": "c2", "created": "2017-03-02T00:00:00UTC", "summary": "admin added FOO"}`,
+			"v14": `{"version": 14, "build": "b2", "config": "c2", "created": "2017-03-03T00:00:00UTC", "summary": "admin deployed foo:v2"}`,
+			"v15": `{"version": 15, "build": "b2", "config": "c3", "created": "2017-03-04T00:00:00UTC", "summary": "admin changed limits for web"}`,
+		}
+
+		version := strings.Trim(strings.TrimPrefix(r.URL.Path, "/v2/apps/foo/releases/"), "/")
+		if version == "" {
+			fmt.Fprintf(w, `{"count": 4, "results": [%s, %s, %s, %s]}`, history["v15"], history["v14"],
+				history["v13"], history["v12"])
+			return
+		}
+
+		fmt.Fprint(w, history[version])
+	})
+
+	server.Mux.HandleFunc("/v2/apps/foo/builds/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprint(w, `{"count": 2, "results": [
+			{"uuid": "b2", "image": "foo:v2", "procfile": {"web": "./server", "worker": "./work"},
+			 "sidecarfile": {}},
+			{"uuid": "b1", "image": "foo:v1", "procfile": {"web": "./server"}, "sidecarfile": {}}
+		]}`)
+	})
+
+	server.Mux.HandleFunc("/v2/apps/foo/config/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprint(w, `{"uuid": "c3", "values": {"FOO": "bar", "DB_PASSWORD": "hunter2"},
+			"memory": {"web": "512M"}}`)
+	})
+
+	var b bytes.Buffer
+	cmdr := DeisCmd{WOut: &b, ConfigFile: cf}
+
+	err = cmdr.ReleasesDiff("foo", 12, 15)
+	assert.NoErr(t, err)
+	assert.Equal(t, b.String(), `=== foo Release Diff v12 v15
+--- v12/build
++++ v15/build
+-image: foo:v1
++image: foo:v2
+ procfile web: ./server
++procfile worker: ./work
+The config was changed by 2 release(s) between v12 and v15:
+v13	2017-03-02T00:00:00UTC	admin added FOO
+v15	2017-03-04T00:00:00UTC	admin changed limits for web
+The config of v15 is the current one:
+memory web=512M
+values DB_PASSWORD=***
+values FOO=bar
+`, "output")
+
 	b.Reset()
+	err = cmdr.ReleasesDiff("foo", 12, 13)
+	assert.NoErr(t, err)
+	assert.Equal(t, b.String(), `=== foo Release Diff v12 v13
+The build is the same.
+The config was changed by 1 release(s) between v12 and v13:
+v13	2017-03-02T00:00:00UTC	admin added FOO
+Only the current config is kept, so the values can't be compared.
+`, "output")
 
-	err = cmdr.ReleasesRollback("angmar", 3)
+	b.Reset()
+	err = cmdr.ReleasesDiff("foo", 13, 14)
+	assert.NoErr(t, err)
+	assert.Equal(t, strings.Contains(b.String(), "The config is the same.\n"), true, "config")
+
+	b.Reset()
+	err = cmdr.ReleasesDiff("foo", 14, 14)
 	assert.NoErr(t, err)
-	assert.Equal(t, testutil.StripProgress(b.String()), "Rolling back to v3... done, v3\n", "output")
+	assert.Equal(t, b.String(), "=== foo Release Diff v14 v14\nThe build is the same.\nThe config is the same.\n", "output")
 }
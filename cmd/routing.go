@@ -14,10 +14,14 @@ func (d *DeisCmd) RoutingInfo(appID string) error {
 	}
 
 	appSettings, err := appsettings.List(s.Client, appID)
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
+	if d.structured() {
+		return d.printStructured(api.AppSettings{Routable: appSettings.Routable})
+	}
+
 	if appSettings.Routable == nil || *appSettings.Routable {
 		d.Println("Routing is enabled.")
 	} else {
@@ -34,10 +38,16 @@ func (d *DeisCmd) RoutingEnable(appID string) error {
 		return err
 	}
 
+	appSettings := api.AppSettings{Routable: api.NewRoutable()}
+
+	if d.DryRun {
+		return d.dryRunAppSettings(s, appID, appSettings)
+	}
+
 	d.Printf("Enabling routing for %s... ", appID)
 
 	quit := progress(d.WOut)
-	appSettings := api.AppSettings{Routable: api.NewRoutable()}
+
 	_, err = appsettings.Set(s.Client, appID, appSettings)
 
 	quit <- true
@@ -59,11 +69,17 @@ func (d *DeisCmd) RoutingDisable(appID string) error {
 		return err
 	}
 
+	appSettings := api.AppSettings{Routable: api.NewRoutable()}
+	*appSettings.Routable = false
+
+	if d.DryRun {
+		return d.dryRunAppSettings(s, appID, appSettings)
+	}
+
 	d.Printf("Disabling routing for %s... ", appID)
 
 	quit := progress(d.WOut)
-	appSettings := api.AppSettings{Routable: api.NewRoutable()}
-	*appSettings.Routable = false
+
 	_, err = appsettings.Set(s.Client, appID, appSettings)
 
 	quit <- true
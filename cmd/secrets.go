@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"path"
+	"strings"
+
+	"github.com/deis/workflow-cli/settings"
+)
+
+// defaultSecretPatterns match the config keys that are treated as secrets.
+var defaultSecretPatterns = []string{"*_PASSWORD", "*_TOKEN", "SECRET*"}
+
+// isSecretKey returns true if a config key matches any of the secret patterns. Keys are
+// matched case insensitively.
+func isSecretKey(key string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(strings.ToUpper(pattern), strings.ToUpper(key)); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// secretMask replaces the value of secret config keys in output.
+const secretMask = "***"
+
+// secretPatterns returns the default secret patterns and those added in the settings file.
+func secretPatterns(s *settings.Settings) []string {
+	return append(append([]string{}, defaultSecretPatterns...), s.SecretPatterns...)
+}
+
+// maskSecrets returns a copy of values where the value of each secret key is masked. Keys
+// set to nil, which unsets them, are left as they are.
+func maskSecrets(values map[string]interface{}, patterns []string) map[string]interface{} {
+	masked := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		if value != nil && isSecretKey(key, patterns) {
+			value = secretMask
+		}
+		masked[key] = value
+	}
+
+	return masked
+}
+
+// maskChanges returns a copy of changes where the old and new values of the secret keys in
+// section are masked.
+func maskChanges(changes []manifestChange, section string, patterns []string) []manifestChange {
+	masked := make([]manifestChange, len(changes))
+	for i, change := range changes {
+		if change.Section == section && isSecretKey(change.Key, patterns) {
+			if change.Old != "" {
+				change.Old = secretMask
+			}
+			if change.New != "" {
+				change.New = secretMask
+			}
+		}
+		masked[i] = change
+	}
+
+	return masked
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/arschles/assert"
+)
+
+func TestIsSecretKey(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]bool{
+		"DATABASE_PASSWORD": true,
+		"github_token":      true,
+		"SECRET_KEY_BASE":   true,
+		"SECRET":            true,
+		"PORT":              false,
+		"TOKEN_URL":         false,
+		"PASSWORD_MIN":      false,
+	}
+
+	for key, expected := range cases {
+		assert.Equal(t, isSecretKey(key, defaultSecretPatterns), expected, key)
+	}
+}
+
+func TestMaskSecrets(t *testing.T) {
+	t.Parallel()
+
+	values := map[string]interface{}{"PORT": "5000", "DATABASE_PASSWORD": "hunter2", "API_KEY": "abc"}
+
+	assert.Equal(t, maskSecrets(values, defaultSecretPatterns), map[string]interface{}{
+		"PORT": "5000", "DATABASE_PASSWORD": "***", "API_KEY": "abc",
+	}, "masked values")
+	assert.Equal(t, maskSecrets(values, []string{"*_KEY"}), map[string]interface{}{
+		"PORT": "5000", "DATABASE_PASSWORD": "hunter2", "API_KEY": "***",
+	}, "masked values")
+	assert.Equal(t, values["DATABASE_PASSWORD"], "hunter2", "original value")
+}
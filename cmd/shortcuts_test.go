@@ -7,8 +7,10 @@ import (
 func TestShortcutsList(t *testing.T) {
 	t.Parallel()
 
-	expected := `create -> apps:create
+	expected := `apply -> apps:apply
+create -> apps:create
 destroy -> apps:destroy
+export -> apps:export
 info -> apps:info
 login -> auth:login
 logout -> auth:logout
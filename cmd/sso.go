@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	deis "github.com/deis/controller-sdk-go"
+	"github.com/deis/workflow-cli/pkg/oidc"
+	"github.com/deis/workflow-cli/pkg/webbrowser"
+	"github.com/deis/workflow-cli/settings"
+)
+
+// openBrowser opens the page to approve an SSO login at, and is replaced in tests.
+var openBrowser = webbrowser.Webbrowser
+
+// defaultSSOScopes are requested when the controller doesn't say which scopes it needs.
+var defaultSSOScopes = []string{"openid", "profile", "email"}
+
+// ssoConfig is the OpenID Connect provider a controller trusts for SSO logins.
+type ssoConfig struct {
+	Issuer   string   `json:"issuer"`
+	ClientID string   `json:"client_id"`
+	Scopes   []string `json:"scopes"`
+}
+
+// LoginSSO logs in to a Deis controller through a single sign-on provider, using the OpenID
+// Connect device authorization flow. The issuer and client ID of the provider come from the
+// controller unless they're given.
+func (d *DeisCmd) LoginSSO(controller, issuer, clientID string, sslVerify bool, credentialStore, caFile,
+	clientCert, clientKey string) error {
+	if d.DryRun {
+		return settings.ErrReadOnly
+	}
+
+	s, err := d.loginSettings(controller, sslVerify, credentialStore, caFile, clientCert, clientKey)
+
+	if err != nil {
+		return err
+	}
+
+	c := s.Client
+	if err = c.CheckConnection(); d.checkAPICompatibility(&s, err) != nil {
+		return err
+	}
+
+	config := ssoConfig{Scopes: defaultSSOScopes}
+	if issuer == "" || clientID == "" {
+		if config, err = d.ssoConfig(&s); err != nil {
+			return err
+		}
+	}
+	if issuer != "" {
+		config.Issuer = issuer
+	}
+	if clientID != "" {
+		config.ClientID = clientID
+	}
+
+	// The provider isn't the controller, so it's verified with the system's CAs and isn't sent
+	// the client certificate, whatever the controller's settings are.
+	provider, err := oidc.Discover(http.DefaultClient, config.Issuer)
+	if err != nil {
+		return err
+	}
+
+	auth, err := provider.AuthorizeDevice(config.ClientID, config.Scopes)
+	if err != nil {
+		return err
+	}
+
+	d.Printf("To log in, open %s and enter the code %s\n", auth.VerificationURI, auth.UserCode)
+
+	page := auth.VerificationURIComplete
+	if page == "" {
+		page = auth.VerificationURI
+	}
+	// Logging in from another machine works too, so a browser that won't open isn't an error.
+	openBrowser(page)
+
+	d.Print("Waiting for the login to be approved... ")
+	quit := progress(d.WOut)
+	token, err := provider.PollToken(config.ClientID, auth)
+	quit <- true
+	<-quit
+
+	if err != nil {
+		d.Println()
+		return err
+	}
+	d.Println("done")
+
+	if token.IDToken == "" {
+		return errors.New("the SSO provider didn't return an ID token, is the openid scope allowed?")
+	}
+
+	if s.Client.Token, s.Username, err = d.ssoExchange(&s, token.IDToken); err != nil {
+		return err
+	}
+	s.LoginMethod, s.SSOIssuer, s.SSOClientID = settings.SSOLogin, issuer, clientID
+
+	filename, err := s.Save(d.ConfigFile)
+	if err != nil {
+		return err
+	}
+
+	d.Printf("Logged in as %s\n", s.Username)
+	d.Printf("Configuration file written to %s\n", filename)
+	return nil
+}
+
+// ssoConfig fetches the SSO provider that the controller trusts.
+func (d *DeisCmd) ssoConfig(s *settings.Settings) (ssoConfig, error) {
+	c := s.Client
+	config := ssoConfig{}
+
+	res, err := c.Request("GET", "/v2/auth/sso/", nil)
+	if err == deis.ErrNotFound {
+		return config, fmt.Errorf("%s doesn't support SSO logins", c.ControllerURL)
+	} else if d.checkAPICompatibility(s, err) != nil {
+		return config, err
+	}
+	defer res.Body.Close()
+
+	if err = json.NewDecoder(res.Body).Decode(&config); err != nil {
+		return config, err
+	}
+
+	if len(config.Scopes) == 0 {
+		config.Scopes = defaultSSOScopes
+	}
+
+	return config, nil
+}
+
+// ssoExchange trades the ID token of an SSO login for a token and username on the controller.
+func (d *DeisCmd) ssoExchange(s *settings.Settings, idToken string) (string, string, error) {
+	c := s.Client
+	body, err := json.Marshal(map[string]string{"id_token": idToken})
+	if err != nil {
+		return "", "", err
+	}
+
+	res, err := c.Request("POST", "/v2/auth/sso/", body)
+	if err == deis.ErrNotFound {
+		return "", "", fmt.Errorf("%s doesn't support SSO logins", c.ControllerURL)
+	} else if d.checkAPICompatibility(s, err) != nil {
+		return "", "", err
+	}
+	defer res.Body.Close()
+
+	login := struct {
+		Token    string `json:"token"`
+		Username string `json:"username"`
+	}{}
+	if err = json.NewDecoder(res.Body).Decode(&login); err != nil {
+		return "", "", err
+	}
+
+	return login.Token, login.Username, nil
+}
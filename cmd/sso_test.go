@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/arschles/assert"
+	"github.com/deis/workflow-cli/pkg/testutil"
+	"github.com/deis/workflow-cli/settings"
+)
+
+func TestLoginSSO(t *testing.T) {
+	defer func(open func(string) error) { openBrowser = open }(openBrowser)
+
+	var opened string
+	openBrowser = func(u string) error {
+		opened = u
+		return errors.New("no browser")
+	}
+
+	idp := testutil.NewTestProvider("deis-cli")
+	defer idp.Close()
+
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	server.Mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprintf(w, `{}`)
+	})
+
+	server.Mux.HandleFunc("/v2/auth/sso/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		if r.Method == "GET" {
+			fmt.Fprintf(w, `{"issuer":"%s","client_id":"deis-cli","scopes":["openid","groups"]}`, idp.Server.URL)
+			return
+		}
+
+		testutil.AssertBody(t, map[string]string{"id_token": "id-token"}, r)
+		fmt.Fprintf(w, `{"token":"abc","username":"jane"}`)
+	})
+
+	var b bytes.Buffer
+	cmdr := DeisCmd{WOut: &b, ConfigFile: cf}
+
+	err = cmdr.LoginSSO(server.Server.URL, "", "", true, "", "", "", "")
+	assert.NoErr(t, err)
+	assert.Equal(t, testutil.StripProgress(b.String()), fmt.Sprintf(`To log in, open %s/device and enter the code WDJB-MJHT
+Waiting for the login to be approved... done
+Logged in as jane
+Configuration file written to %s
+`, idp.Server.URL, cf), "output")
+	assert.Equal(t, opened, idp.Server.URL+"/device?user_code=WDJB-MJHT", "opened page")
+
+	s, err := settings.Load(cf)
+	assert.NoErr(t, err)
+	assert.Equal(t, s.Username, "jane", "username")
+	assert.Equal(t, s.Client.Token, "abc", "token")
+}
+
+func TestLoginSSOUnsupported(t *testing.T) {
+	t.Parallel()
+
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	server.Mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprintf(w, `{}`)
+	})
+
+	server.Mux.HandleFunc("/v2/auth/sso/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	var b bytes.Buffer
+	cmdr := DeisCmd{WOut: &b, ConfigFile: cf}
+
+	err = cmdr.LoginSSO(server.Server.URL, "", "", true, "", "", "", "")
+	assert.Err(t, fmt.Errorf("%s doesn't support SSO logins", server.Server.URL), err)
+}
+
+func TestLoginSSOVerifiesProvider(t *testing.T) {
+	t.Parallel()
+
+	// The provider's certificate is self-signed, so it's only trusted if the controller's
+	// ssl_verify=false leaks to it.
+	idp := httptest.NewUnstartedServer(http.NotFoundHandler())
+	// Don't log the handshake that fails on purpose.
+	idp.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	idp.StartTLS()
+	defer idp.Close()
+
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	server.Mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprintf(w, `{}`)
+	})
+
+	var b bytes.Buffer
+	cmdr := DeisCmd{WOut: &b, ConfigFile: cf}
+
+	err = cmdr.LoginSSO(server.Server.URL, idp.URL, "deis-cli", false, "", "", "", "")
+	assert.ExistsErr(t, err, "unverified provider")
+	if !strings.Contains(err.Error(), "certificate") {
+		t.Errorf("expected a certificate error, got %v", err)
+	}
+}
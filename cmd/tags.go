@@ -19,10 +19,14 @@ func (d *DeisCmd) TagsList(appID string) error {
 	}
 
 	config, err := config.List(s.Client, appID)
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
+	if d.structured() {
+		return d.printStructured(api.Config{Tags: config.Tags})
+	}
+
 	d.Printf("=== %s Tags\n", appID)
 
 	tagMap := make(map[string]string)
@@ -49,16 +53,21 @@ func (d *DeisCmd) TagsSet(appID string, tags []string) error {
 		return err
 	}
 
+	configObj := api.Config{}
+	configObj.Tags = tagsMap
+
+	if d.DryRun {
+		return d.dryRunConfig(s, appID, configObj, false)
+	}
+
 	d.Print("Applying tags... ")
 
 	quit := progress(d.WOut)
-	configObj := api.Config{}
-	configObj.Tags = tagsMap
 
 	_, err = config.Set(s.Client, appID, configObj)
 	quit <- true
 	<-quit
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
@@ -75,10 +84,6 @@ func (d *DeisCmd) TagsUnset(appID string, tags []string) error {
 		return err
 	}
 
-	d.Print("Applying tags... ")
-
-	quit := progress(d.WOut)
-
 	configObj := api.Config{}
 
 	tagsMap := make(map[string]interface{})
@@ -89,10 +94,18 @@ func (d *DeisCmd) TagsUnset(appID string, tags []string) error {
 
 	configObj.Tags = tagsMap
 
+	if d.DryRun {
+		return d.dryRunConfig(s, appID, configObj, false)
+	}
+
+	d.Print("Applying tags... ")
+
+	quit := progress(d.WOut)
+
 	_, err = config.Set(s.Client, appID, configObj)
 	quit <- true
 	<-quit
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
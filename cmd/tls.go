@@ -11,10 +11,14 @@ func (d *DeisCmd) TLSInfo(appID string) error {
 	}
 
 	tls, err := tls.Info(s.Client, appID)
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
+	if d.structured() {
+		return d.printStructured(tls)
+	}
+
 	d.Printf("=== %s TLS\n", appID)
 	d.Println(tls)
 
@@ -29,13 +33,17 @@ func (d *DeisCmd) TLSEnable(appID string) error {
 		return err
 	}
 
+	if d.DryRun {
+		return d.dryRunTLS(s, appID, true)
+	}
+
 	d.Printf("Enabling https-only requests for %s... ", appID)
 
 	quit := progress(d.WOut)
 	_, err = tls.Enable(s.Client, appID)
 	quit <- true
 	<-quit
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
@@ -51,13 +59,17 @@ func (d *DeisCmd) TLSDisable(appID string) error {
 		return err
 	}
 
+	if d.DryRun {
+		return d.dryRunTLS(s, appID, false)
+	}
+
 	d.Printf("Disabling https-only requests for %s... ", appID)
 
 	quit := progress(d.WOut)
 	_, err = tls.Disable(s.Client, appID)
 	quit <- true
 	<-quit
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
@@ -20,9 +20,13 @@ func (d *DeisCmd) TolerationList(appID string, format string) error {
 	}
 
 	config, err := config.List(settings.Client, appID)
-	if d.checkAPICompatibility(settings.Client, err) != nil {
+	if d.checkAPICompatibility(settings, err) != nil {
 		return err
 	}
+
+	if d.structured() {
+		return d.printStructured(api.Config{Tolerations: config.Tolerations})
+	}
 	var configOutput strings.Builder
 
 	appTypes := make([]string, 0, len(config.Tolerations))
@@ -98,18 +102,23 @@ func (d *DeisCmd) TolerationSet(appID string, appType string, identifier string,
 		return err
 	}
 
+	appTolerations := make(map[string]map[string]*v1.Toleration)
+	appTolerations[appType] = map[string]*v1.Toleration{identifier: &toleration}
+	configObj := api.Config{Tolerations: appTolerations}
+
+	if d.DryRun {
+		return d.dryRunConfig(settings, appID, configObj, false)
+	}
+
 	d.Print("Creating Tolerations... ")
 
 	quit := progress(d.WOut)
 
-	appTolerations := make(map[string]map[string]*v1.Toleration)
-	appTolerations[appType] = map[string]*v1.Toleration{identifier: &toleration}
-	configObj := api.Config{Tolerations: appTolerations}
 	configObj, err = config.Set(settings.Client, appID, configObj)
 
 	quit <- true
 	<-quit
-	if d.checkAPICompatibility(settings.Client, err) != nil {
+	if d.checkAPICompatibility(settings, err) != nil {
 		return err
 	}
 
@@ -126,10 +135,6 @@ func (d *DeisCmd) TolerationUnset(appID string, appType string, tolerationIdenti
 		return err
 	}
 
-	d.Print("Removing Tolerations... ")
-
-	quit := progress(d.WOut)
-
 	valuesMap := make(map[string]*v1.Toleration)
 	for _, identifier := range tolerationIdentifiers {
 		valuesMap[identifier] = nil
@@ -138,11 +143,20 @@ func (d *DeisCmd) TolerationUnset(appID string, appType string, tolerationIdenti
 	appTolerations := make(map[string]map[string]*v1.Toleration)
 	appTolerations[appType] = valuesMap
 	configObj := api.Config{Tolerations: appTolerations}
+
+	if d.DryRun {
+		return d.dryRunConfig(settings, appID, configObj, false)
+	}
+
+	d.Print("Removing Tolerations... ")
+
+	quit := progress(d.WOut)
+
 	configObj, err = config.Set(settings.Client, appID, configObj)
 
 	quit <- true
 	<-quit
-	if d.checkAPICompatibility(settings.Client, err) != nil {
+	if d.checkAPICompatibility(settings, err) != nil {
 		return err
 	}
 
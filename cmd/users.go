@@ -18,10 +18,14 @@ func (d *DeisCmd) UsersList(results int) error {
 	}
 
 	users, count, err := users.List(s.Client, results)
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
+	if d.structured() {
+		return d.printStructured(users)
+	}
+
 	d.Printf("=== Users (*=admin)%s", limitCount(len(users), count))
 
 	for _, user := range users {
@@ -10,6 +10,7 @@ import (
 	deis "github.com/deis/controller-sdk-go"
 	"github.com/deis/workflow-cli/pkg/git"
 	"github.com/deis/workflow-cli/settings"
+	"golang.org/x/crypto/ssh/terminal"
 )
 
 var defaultLimit = -1
@@ -37,6 +38,22 @@ func progress(wOut io.Writer) chan bool {
 	return quit
 }
 
+// timestampLayouts are the formats of the timestamps the controller returns, such as
+// 2017-03-10T16:33:09UTC.
+var timestampLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05MST", "2006-01-02T15:04:05"}
+
+// parseTimestamp parses a timestamp returned by the controller, returning false if it isn't
+// in any of the known formats.
+func parseTimestamp(value string) (time.Time, bool) {
+	for _, layout := range timestampLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
 // load loads settings file and looks up the app name
 func load(cf string, appID string) (*settings.Settings, string, error) {
 	s, err := settings.Load(cf)
@@ -74,9 +91,11 @@ func limitCount(objs, total int) string {
 	return fmt.Sprintf(" (%d of %d)\n", objs, total)
 }
 
-// checkAPICompatibility handles specific behavior for certain errors,
-// such as printing an warning for the API mismatch error
-func (d *DeisCmd) checkAPICompatibility(c *deis.Client, err error) error {
+// checkAPICompatibility handles specific behavior for certain errors, such as printing an
+// warning for the API mismatch error. s is the settings the request was made with.
+func (d *DeisCmd) checkAPICompatibility(s *settings.Settings, err error) error {
+	c := s.Client
+
 	if err == deis.ErrAPIMismatch {
 		if !d.Warned {
 			d.PrintErrf(`!    WARNING: Client and server API versions do not match. Please consider upgrading.
@@ -90,5 +109,93 @@ func (d *DeisCmd) checkAPICompatibility(c *deis.Client, err error) error {
 		return nil
 	}
 
+	// Without a token, the user isn't logged in yet, so a login or registration failed.
+	if err == deis.ErrUnauthorized && c.Token != "" {
+		d.expired = &SessionExpiredError{Controller: c.ControllerURL.String(), Username: s.Username}
+		d.session = s
+		return *d.expired
+	}
+
 	return err
 }
+
+// SessionExpiredError is returned when the controller no longer accepts the token of the
+// logged in user, such as after it was regenerated on another machine.
+type SessionExpiredError struct {
+	Controller string
+	Username   string
+}
+
+func (e SessionExpiredError) Error() string {
+	return fmt.Sprintf("session expired for %s as %s, use 'deis login %s' to log in again",
+		e.Controller, e.Username, e.Controller)
+}
+
+// SessionError returns the error a command failed with, or a SessionExpiredError if the
+// controller rejected the token. Commands return the SDK's error after checking it, so it's
+// swapped for the one checkAPICompatibility found here.
+func (d *DeisCmd) SessionError(err error) error {
+	if err == deis.ErrUnauthorized && d.expired != nil {
+		return *d.expired
+	}
+
+	return err
+}
+
+// Relogin offers to log in again when a command failed because the session expired, if the
+// input is a terminal. It returns whether the user logged in, so the command can be retried.
+func (d *DeisCmd) Relogin(err error) bool {
+	expired, ok := err.(SessionExpiredError)
+	if !ok || d.session == nil || !isTerminal(d.WIn) {
+		return false
+	}
+
+	return d.relogin(d.session, expired)
+}
+
+// relogin asks whether to log in again, the same way as the settings s were logged in, and
+// does. Everything is written to WErr, so the output of the retried command isn't mixed with it.
+func (d *DeisCmd) relogin(s *settings.Settings, expired SessionExpiredError) bool {
+	// Logging in again wouldn't replace a token given in the environment.
+	if s.Sources["token"] == "$"+settings.TokenEnv {
+		return false
+	}
+
+	d.PrintErrf("The session expired for %s as %s.\n", expired.Controller, expired.Username)
+	d.PrintErr("Log in again and retry? (y/N) ")
+
+	var answer string
+	fmt.Fscanln(d.WIn, &answer)
+
+	if answer = strings.ToLower(answer); answer != "y" && answer != "yes" {
+		return false
+	}
+
+	out := d.WOut
+	d.WOut = d.WErr
+	defer func() { d.WOut = out }()
+
+	var err error
+	switch s.LoginMethod {
+	case settings.SSOLogin:
+		err = d.LoginSSO(expired.Controller, s.SSOIssuer, s.SSOClientID, s.Client.VerifySSL, "", "", "", "")
+	case settings.GoogleLogin:
+		err = d.Login(expired.Controller, expired.Username, "", s.Client.VerifySSL, true, "", "", "", "")
+	default:
+		err = d.Login(expired.Controller, expired.Username, "", s.Client.VerifySSL, false, "", "", "", "")
+	}
+
+	if err != nil {
+		d.PrintErrf("Error: %v\n", err)
+		return false
+	}
+
+	d.expired, d.session = nil, nil
+	return true
+}
+
+// isTerminal returns whether a reader is an interactive terminal.
+func isTerminal(r io.Reader) bool {
+	file, ok := r.(*os.File)
+	return ok && terminal.IsTerminal(int(file.Fd()))
+}
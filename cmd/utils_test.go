@@ -2,14 +2,18 @@ package cmd
 
 import (
 	"bytes"
+	"fmt"
 	"io/ioutil"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/arschles/assert"
 	"github.com/deis/controller-sdk-go"
 	"github.com/deis/workflow-cli/pkg/git"
+	"github.com/deis/workflow-cli/pkg/testutil"
 	"github.com/deis/workflow-cli/settings"
 )
 
@@ -66,9 +70,9 @@ func TestAPICompatibility(t *testing.T) {
 	t.Parallel()
 	var b bytes.Buffer
 	cmdr := DeisCmd{WErr: &b, ConfigFile: ""}
-	client := deis.Client{ControllerAPIVersion: "v1.0"}
+	s := settings.Settings{Client: &deis.Client{ControllerAPIVersion: "v1.0"}}
 
-	err := cmdr.checkAPICompatibility(&client, deis.ErrAPIMismatch)
+	err := cmdr.checkAPICompatibility(&s, deis.ErrAPIMismatch)
 	assert.NoErr(t, err)
 	assert.Equal(t, b.String(), `!    WARNING: Client and server API versions do not match. Please consider upgrading.
 !    Client version: 2.3
@@ -77,12 +81,109 @@ func TestAPICompatibility(t *testing.T) {
 
 	// After being warned once, the warning should not be printed again.
 	b.Reset()
-	err = cmdr.checkAPICompatibility(&client, deis.ErrAPIMismatch)
+	err = cmdr.checkAPICompatibility(&s, deis.ErrAPIMismatch)
 	assert.NoErr(t, err)
 	assert.Equal(t, b.String(), "", "output")
 
 	b.Reset()
-	err = cmdr.checkAPICompatibility(&client, deis.ErrConflict)
+	err = cmdr.checkAPICompatibility(&s, deis.ErrConflict)
 	assert.Err(t, deis.ErrConflict, err)
 	assert.Equal(t, b.String(), "", "output")
 }
+
+func TestSessionExpired(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	server.Mux.HandleFunc("/v2/apps/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprintf(w, `{"detail":"Invalid token."}`)
+	})
+
+	// Not being logged in isn't an expired session.
+	var b bytes.Buffer
+	cmdr := DeisCmd{WOut: &b, WIn: strings.NewReader("y\n"), ConfigFile: cf}
+	err = cmdr.AppsList(-1)
+	assert.Err(t, deis.ErrUnauthorized, cmdr.SessionError(err))
+
+	s, err := settings.Load(cf)
+	assert.NoErr(t, err)
+	s.Client.Token = "revoked"
+	_, err = s.Save(cf)
+	assert.NoErr(t, err)
+
+	cmdr = DeisCmd{WOut: &b, WIn: strings.NewReader("y\n"), ConfigFile: cf}
+	err = cmdr.AppsList(-1)
+	err = cmdr.SessionError(err)
+	assert.Err(t, SessionExpiredError{Controller: server.Server.URL, Username: "test"}, err)
+	assert.Equal(t, err.Error(), fmt.Sprintf(
+		"session expired for %s as test, use 'deis login %s' to log in again", server.Server.URL,
+		server.Server.URL), "error")
+
+	// Logging in again needs a terminal.
+	assert.Equal(t, cmdr.Relogin(err), false, "relogin")
+	assert.Equal(t, b.String(), "", "output")
+}
+
+func TestRelogin(t *testing.T) {
+	defer func(open func(string) error) { openBrowser = open }(openBrowser)
+	openBrowser = func(string) error { return nil }
+
+	idp := testutil.NewTestProvider("deis-cli")
+	defer idp.Close()
+
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	server.Mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprintf(w, `{}`)
+	})
+
+	server.Mux.HandleFunc("/v2/auth/sso/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"token":"abc","username":"test"}`)
+	})
+
+	s, err := settings.Load(cf)
+	assert.NoErr(t, err)
+	s.Client.Token = "revoked"
+	s.LoginMethod, s.SSOIssuer, s.SSOClientID = settings.SSOLogin, idp.Server.URL, "deis-cli"
+	_, err = s.Save(cf)
+	assert.NoErr(t, err)
+
+	var b, e bytes.Buffer
+	cmdr := DeisCmd{WOut: &b, WErr: &e, WIn: strings.NewReader("y\n"), ConfigFile: cf}
+	expired := SessionExpiredError{Controller: server.Server.URL, Username: "test"}
+
+	// Logging in again wouldn't replace the token in the environment.
+	os.Setenv(settings.TokenEnv, "revoked")
+	s, err = settings.Load(cf)
+	os.Unsetenv(settings.TokenEnv)
+	assert.NoErr(t, err)
+	assert.Equal(t, cmdr.relogin(s, expired), false, "relogin")
+	assert.Equal(t, e.String(), "", "errors")
+
+	// The session is renewed the way the user logged in, without writing to the output.
+	s, err = settings.Load(cf)
+	assert.NoErr(t, err)
+	assert.Equal(t, cmdr.relogin(s, expired), true, "relogin")
+	assert.Equal(t, b.String(), "", "output")
+	if !strings.Contains(e.String(), "Log in again and retry? (y/N) To log in, open "+idp.Server.URL) {
+		t.Errorf("expected an SSO login, got %q", e.String())
+	}
+
+	s, err = settings.Load(cf)
+	assert.NoErr(t, err)
+	assert.Equal(t, s.Client.Token, "abc", "token")
+	assert.Equal(t, s.LoginMethod, settings.SSOLogin, "login method")
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/deis/controller-sdk-go/apps"
+	"github.com/deis/controller-sdk-go/ps"
+	"github.com/deis/controller-sdk-go/releases"
+	"github.com/deis/workflow-cli/settings"
+)
+
+// failedPodStates are the states of pods that won't come up without intervention, such as
+// crash-looping ones.
+var failedPodStates = map[string]bool{"crashed": true, "error": true}
+
+// waitInterval is how often processes are polled when waiting for them.
+var waitInterval = 2 * time.Second
+
+// waitForLatestRelease waits for processes to be up on the app's latest release.
+func (d *DeisCmd) waitForLatestRelease(s *settings.Settings, appID string, types []string,
+	timeout time.Duration) error {
+	version, err := d.latestRelease(s, appID)
+	if err != nil {
+		return err
+	}
+
+	return d.waitForProcesses(s, appID, version, types, timeout)
+}
+
+// latestRelease returns the version of an app's latest release.
+func (d *DeisCmd) latestRelease(s *settings.Settings, appID string) (int, error) {
+	appReleases, _, err := releases.List(s.Client, appID, 1)
+	if d.checkAPICompatibility(s, err) != nil {
+		return 0, err
+	}
+
+	version := 0
+	for _, release := range appReleases {
+		if release.Version > version {
+			version = release.Version
+		}
+	}
+
+	return version, nil
+}
+
+// waitForProcesses polls an app's processes until every pod of the given types, or of all
+// types if there are none, is up on a release, and there are as many as the app is scaled
+// to. It prints the progress of each type, and the processes once they are all up. It fails
+// if a pod of the release is crashing or the timeout expires.
+func (d *DeisCmd) waitForProcesses(s *settings.Settings, appID string, version int,
+	types []string, timeout time.Duration) error {
+	app, err := apps.Get(s.Client, appID)
+	if d.checkAPICompatibility(s, err) != nil {
+		return err
+	}
+
+	if len(types) == 0 {
+		for psType := range app.Structure {
+			types = append(types, psType)
+		}
+	}
+	sort.Strings(types)
+
+	release := fmt.Sprintf("v%d", version)
+	d.Printf("Waiting for processes to be up on %s...\n", release)
+
+	deadline := time.Now().Add(timeout)
+	statuses := make(map[string]string)
+
+	for {
+		processes, _, err := ps.List(s.Client, appID, s.Limit)
+		if d.checkAPICompatibility(s, err) != nil {
+			return err
+		}
+
+		converged := true
+		for _, psType := range types {
+			up, total := 0, 0
+			for _, pod := range processes {
+				if pod.Type != psType {
+					continue
+				}
+				total++
+
+				if pod.Release != release {
+					continue
+				}
+				if failedPodStates[pod.State] {
+					return fmt.Errorf("process %s is %s on %s, see 'deis logs -a %s'", pod.Name, pod.State,
+						release, appID)
+				}
+				if pod.State == "up" {
+					up++
+				}
+			}
+
+			desired := app.Structure[psType]
+			if up != desired || total != desired {
+				converged = false
+			}
+
+			status := fmt.Sprintf("%d/%d up", up, desired)
+			if total > up {
+				status += fmt.Sprintf(", %d pending", total-up)
+			}
+			if statuses[psType] != status {
+				d.Printf("%s: %s\n", psType, status)
+				statuses[psType] = status
+			}
+		}
+
+		if converged {
+			printProcesses(appID, processes, d.WOut)
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out after %s waiting for processes to be up on %s", timeout, release)
+		}
+
+		time.Sleep(waitInterval)
+	}
+}
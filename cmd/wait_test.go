@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/arschles/assert"
+	"github.com/deis/workflow-cli/pkg/testutil"
+)
+
+// servePods serves the app foo at release v3, scaled to two web and one worker processes,
+// with the pods of each poll taken from polls in turn.
+func servePods(server *testutil.TestServer, polls []string) *int {
+	count := 0
+
+	server.Mux.HandleFunc("/v2/apps/foo/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"id": "foo", "structure": {"web": 2, "worker": 1}}`)
+	})
+
+	server.Mux.HandleFunc("/v2/apps/foo/releases/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"count": 3, "results": [{"version": 3}]}`)
+	})
+
+	server.Mux.HandleFunc("/v2/apps/foo/pods/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		pods := polls[len(polls)-1]
+		if count < len(polls) {
+			pods = polls[count]
+		}
+		count++
+		fmt.Fprintf(w, `{"count": 3, "results": [%s]}`, pods)
+	})
+
+	return &count
+}
+
+func pod(name, release, state string) string {
+	return fmt.Sprintf(`{"release": "%s", "type": "%s", "name": "foo-%s-4084101150-c871y", "state": "%s"}`,
+		release, name[:len(name)-1], name, state)
+}
+
+func TestPsWait(t *testing.T) {
+	waitInterval = time.Millisecond
+	defer func() { waitInterval = 2 * time.Second }()
+
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	polls := servePods(server, []string{
+		pod("web1", "v2", "up") + "," + pod("web2", "v3", "starting") + "," + pod("worker1", "v3", "up"),
+		pod("web1", "v3", "up") + "," + pod("web2", "v3", "starting") + "," + pod("worker1", "v3", "up"),
+		pod("web1", "v3", "up") + "," + pod("web2", "v3", "up") + "," + pod("worker1", "v3", "up"),
+	})
+
+	var b bytes.Buffer
+	cmdr := DeisCmd{WOut: &b, ConfigFile: cf}
+
+	err = cmdr.PsWait("foo", nil, time.Minute)
+	assert.NoErr(t, err)
+	assert.Equal(t, *polls, 3, "polls")
+	assert.Equal(t, b.String(), `Waiting for processes to be up on v3...
+web: 0/2 up, 2 pending
+worker: 1/1 up
+web: 1/2 up, 1 pending
+web: 2/2 up
+=== foo Processes
+--- web:
+foo-web1-4084101150-c871y up (v3)
+foo-web2-4084101150-c871y up (v3)
+--- worker:
+foo-worker1-4084101150-c871y up (v3)
+`, "output")
+}
+
+func TestPsWaitCrashing(t *testing.T) {
+	waitInterval = time.Millisecond
+	defer func() { waitInterval = 2 * time.Second }()
+
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	servePods(server, []string{
+		pod("web1", "v3", "starting") + "," + pod("worker1", "v3", "up"),
+		pod("web1", "v3", "crashed") + "," + pod("worker1", "v3", "up"),
+	})
+
+	var b bytes.Buffer
+	cmdr := DeisCmd{WOut: &b, ConfigFile: cf}
+
+	err = cmdr.PsWait("foo", []string{"web"}, time.Minute)
+	assert.Err(t, fmt.Errorf("process foo-web1-4084101150-c871y is crashed on v3, see 'deis logs -a foo'"), err)
+	assert.Equal(t, b.String(), `Waiting for processes to be up on v3...
+web: 0/2 up, 1 pending
+`, "output")
+}
+
+func TestPsWaitTimeout(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	servePods(server, []string{pod("worker1", "v2", "up")})
+
+	var b bytes.Buffer
+	cmdr := DeisCmd{WOut: &b, ConfigFile: cf}
+
+	err = cmdr.PsWait("foo", []string{"worker"}, 0)
+	assert.Err(t, fmt.Errorf("timed out after 0s waiting for processes to be up on v3"), err)
+	assert.Equal(t, b.String(), `Waiting for processes to be up on v3...
+worker: 0/1 up, 1 pending
+`, "output")
+}
@@ -15,10 +15,14 @@ func (d *DeisCmd) WhitelistList(appID string) error {
 	}
 
 	whitelist, err := whitelist.List(s.Client, appID)
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
+	if d.structured() {
+		return d.printStructured(whitelist)
+	}
+
 	d.Printf("=== %s Whitelisted Addresses\n", appID)
 
 	for _, ip := range whitelist.Addresses {
@@ -35,13 +39,17 @@ func (d *DeisCmd) WhitelistAdd(appID, IPs string) error {
 		return err
 	}
 
+	if d.DryRun {
+		return d.dryRunWhitelist(s, appID, strings.Split(IPs, ","), false)
+	}
+
 	d.Printf("Adding %s to %s whitelist...\n", IPs, appID)
 
 	quit := progress(d.WOut)
 	_, err = whitelist.Add(s.Client, appID, strings.Split(IPs, ","))
 	quit <- true
 	<-quit
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
@@ -57,13 +65,17 @@ func (d *DeisCmd) WhitelistRemove(appID, IPs string) error {
 		return err
 	}
 
+	if d.DryRun {
+		return d.dryRunWhitelist(s, appID, strings.Split(IPs, ","), true)
+	}
+
 	d.Printf("Removing %s from %s whitelist...\n", IPs, appID)
 
 	quit := progress(d.WOut)
 	err = whitelist.Delete(s.Client, appID, strings.Split(IPs, ","))
 	quit <- true
 	<-quit
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(s, err) != nil {
 		return err
 	}
 
@@ -11,6 +11,7 @@ import (
 	"github.com/deis/workflow-cli/cli"
 	"github.com/deis/workflow-cli/cmd"
 	"github.com/deis/workflow-cli/parser"
+	"github.com/deis/workflow-cli/settings"
 	docopt "github.com/docopt/docopt-go"
 )
 
@@ -36,8 +37,23 @@ Options:
     display client version
   -c --config=<config>
     path to configuration file. Equivalent to
-    setting $DEIS_PROFILE. Defaults to ~/.deis/config.json.
+    setting $DEIS_PROFILE. Defaults to the profile selected with
+    'deis profiles:use', or ~/.deis/client.json.
     If value is not a filepath, will assume location ~/.deis/client.json
+  --output=<format>
+    output format of list and info commands, one of: table, json, yaml.
+    Defaults to table.
+  --dry-run
+    show the changes a command would make instead of sending them to the controller.
+
+Environment variables override the settings in the configuration file, and flags
+override both, so CI can run without a configuration file:
+
+  DEIS_CONTROLLER      the controller URL, which is enough to run without a file
+  DEIS_TOKEN           the token of the user
+  DEIS_USERNAME        the name of the user
+  DEIS_SSL_VERIFY      whether to verify SSL certificates, true or false
+  DEIS_RESPONSE_LIMIT  the number of results that list commands return
 
 Auth commands, use 'deis help auth' to learn more::
 
@@ -60,6 +76,7 @@ Subcommands, use 'deis help [subcommand]' to learn more::
   labels        manage labels of application
   limits        manage resource limits for your application
   perms         manage permissions for applications
+  profiles      manage client profiles for multiple controllers
   ps            manage processes inside an app container
   registry      manage private registry information for your application
   releases      manage releases of an application
@@ -103,84 +120,111 @@ Use 'git push deis master' to deploy to an application.
 	configFlag := getConfigFlag(argv)
 	// Don't pass down config flag to parser because it isn't defined there.
 	argv = removeConfigFlag(argv)
-	cmdr := cmd.DeisCmd{ConfigFile: configFlag, WOut: wOut, WErr: wErr, WIn: wIn}
+
+	outputFlag := getOutputFlag(argv)
+	// The output flag is global as well, so strip it before parsing.
+	argv = removeOutputFlag(argv)
+	if err = cmd.ValidOutputFormat(outputFlag); err != nil {
+		fmt.Fprintf(wErr, "Error: %v\n", err)
+		return 1
+	}
+
+	dryRunFlag := getDryRunFlag(argv)
+	argv = removeDryRunFlag(argv)
+	// Commands that can't show what they would change fail instead of making changes.
+	settings.ReadOnly = dryRunFlag
+
+	cmdr := cmd.DeisCmd{ConfigFile: configFlag, Output: outputFlag, DryRun: dryRunFlag, WOut: wOut, WErr: wErr, WIn: wIn}
 
 	// Dispatch the command, passing the argv through so subcommands can
 	// re-parse it according to their usage strings.
-	switch command {
-	case "annotation":
-		err = parser.Annotation(argv, &cmdr)
-	case "apps":
-		err = parser.Apps(argv, &cmdr)
-	case "auth":
-		err = parser.Auth(argv, &cmdr)
-	case "autoscale":
-		err = parser.Autoscale(argv, &cmdr)
-	case "builds":
-		err = parser.Builds(argv, &cmdr)
-	case "certs":
-		err = parser.Certs(argv, &cmdr)
-	case "config":
-		err = parser.Config(argv, &cmdr)
-	case "domains":
-		err = parser.Domains(argv, &cmdr)
-	case "git":
-		err = parser.Git(argv, &cmdr)
-	case "healthchecks":
-		err = parser.Healthchecks(argv, &cmdr)
-	case "help":
-		fmt.Fprint(os.Stdout, usage)
-		return 0
-	case "keys":
-		err = parser.Keys(argv, &cmdr)
-	case "labels":
-		err = parser.Labels(argv, &cmdr)
-	case "limits":
-		err = parser.Limits(argv, &cmdr)
-	case "perms":
-		err = parser.Perms(argv, &cmdr)
-	case "ps":
-		err = parser.Ps(argv, &cmdr)
-	case "registry":
-		err = parser.Registry(argv, &cmdr)
-	case "releases":
-		err = parser.Releases(argv, &cmdr)
-	case "routing":
-		err = parser.Routing(argv, &cmdr)
-	case "maintenance":
-		err = parser.Maintenance(argv, &cmdr)
-	case "shortcuts":
-		err = parser.Shortcuts(argv, &cmdr)
-	case "tags":
-		err = parser.Tags(argv, &cmdr)
-	case "tls":
-		err = parser.TLS(argv, &cmdr)
-	case "toleration":
-		err = parser.Toleration(argv, &cmdr)
-	case "users":
-		err = parser.Users(argv, &cmdr)
-	case "version":
-		err = parser.Version(argv, &cmdr)
-	case "whitelist":
-		err = parser.Whitelist(argv, &cmdr)
-	default:
-		env := os.Environ()
-
-		binary, err := exec.LookPath(extensionPrefix + command)
-		if err != nil {
-			parser.PrintUsage(&cmdr)
-			return 1
+	for {
+		switch command {
+		case "annotation":
+			err = parser.Annotation(argv, &cmdr)
+		case "apps":
+			err = parser.Apps(argv, &cmdr)
+		case "auth":
+			err = parser.Auth(argv, &cmdr)
+		case "autoscale":
+			err = parser.Autoscale(argv, &cmdr)
+		case "builds":
+			err = parser.Builds(argv, &cmdr)
+		case "certs":
+			err = parser.Certs(argv, &cmdr)
+		case "config":
+			err = parser.Config(argv, &cmdr)
+		case "domains":
+			err = parser.Domains(argv, &cmdr)
+		case "git":
+			err = parser.Git(argv, &cmdr)
+		case "healthchecks":
+			err = parser.Healthchecks(argv, &cmdr)
+		case "help":
+			fmt.Fprint(os.Stdout, usage)
+			return 0
+		case "keys":
+			err = parser.Keys(argv, &cmdr)
+		case "labels":
+			err = parser.Labels(argv, &cmdr)
+		case "limits":
+			err = parser.Limits(argv, &cmdr)
+		case "perms":
+			err = parser.Perms(argv, &cmdr)
+		case "profiles":
+			err = parser.Profiles(argv, &cmdr)
+		case "ps":
+			err = parser.Ps(argv, &cmdr)
+		case "registry":
+			err = parser.Registry(argv, &cmdr)
+		case "releases":
+			err = parser.Releases(argv, &cmdr)
+		case "routing":
+			err = parser.Routing(argv, &cmdr)
+		case "maintenance":
+			err = parser.Maintenance(argv, &cmdr)
+		case "shortcuts":
+			err = parser.Shortcuts(argv, &cmdr)
+		case "tags":
+			err = parser.Tags(argv, &cmdr)
+		case "tls":
+			err = parser.TLS(argv, &cmdr)
+		case "toleration":
+			err = parser.Toleration(argv, &cmdr)
+		case "users":
+			err = parser.Users(argv, &cmdr)
+		case "version":
+			err = parser.Version(argv, &cmdr)
+		case "whitelist":
+			err = parser.Whitelist(argv, &cmdr)
+		default:
+			env := os.Environ()
+
+			binary, err := exec.LookPath(extensionPrefix + command)
+			if err != nil {
+				parser.PrintUsage(&cmdr)
+				return 1
+			}
+
+			cmdArgv := prepareCmdArgs(command, argv)
+
+			err = syscall.Exec(binary, cmdArgv, env)
+			if err != nil {
+				parser.PrintUsage(&cmdr)
+				return 1
+			}
 		}
 
-		cmdArgv := prepareCmdArgs(command, argv)
-
-		err = syscall.Exec(binary, cmdArgv, env)
-		if err != nil {
-			parser.PrintUsage(&cmdr)
-			return 1
+		// A command that failed because the session expired runs again once the user logs in.
+		err = cmdr.SessionError(err)
+		if !cmdr.Relogin(err) {
+			break
 		}
 	}
 	if err != nil {
+		if exitErr, ok := err.(cmd.ExitError); ok {
+			return exitErr.Code
+		}
 		fmt.Fprintf(wErr, "Error: %v\n", err)
 		return 1
 	}
@@ -215,6 +259,67 @@ func getConfigFlag(argv []string) string {
 	return ""
 }
 
+func removeOutputFlag(argv []string) []string {
+	var kept []string
+	for i, arg := range argv {
+		// Arguments after -- belong to the command being run, such as with apps:run.
+		if arg == "--" {
+			return append(kept, argv[i:]...)
+		} else if arg == "--output" || strings.HasPrefix(arg, "--output=") {
+			continue
+			// If the previous option is --output, remove the argument as well
+		} else if i != 0 && argv[i-1] == "--output" {
+			continue
+		}
+
+		kept = append(kept, arg)
+	}
+
+	return kept
+}
+
+func getOutputFlag(argv []string) string {
+	for i, arg := range argv {
+		if arg == "--" {
+			return ""
+		} else if strings.HasPrefix(arg, "--output=") {
+			return strings.TrimPrefix(arg, "--output=")
+		} else if i != 0 && argv[i-1] == "--output" {
+			return arg
+		}
+	}
+
+	return ""
+}
+
+func removeDryRunFlag(argv []string) []string {
+	var kept []string
+	for i, arg := range argv {
+		// Arguments after -- belong to the command being run, such as with apps:run.
+		if arg == "--" {
+			return append(kept, argv[i:]...)
+		} else if arg == "--dry-run" {
+			continue
+		}
+
+		kept = append(kept, arg)
+	}
+
+	return kept
+}
+
+func getDryRunFlag(argv []string) bool {
+	for _, arg := range argv {
+		if arg == "--" {
+			return false
+		} else if arg == "--dry-run" {
+			return true
+		}
+	}
+
+	return false
+}
+
 // parseArgs returns the provided args with "--help" as the last arg if need be,
 // expands shortcuts and formats commands to be properly routed.
 func parseArgs(argv []string) (string, []string) {
@@ -157,3 +157,78 @@ func TestRemoveConfigFlag(t *testing.T) {
 	actual = removeConfigFlag(argv)
 	assert.Equal(t, actual, expected, "args")
 }
+
+func TestGetOutputFlag(t *testing.T) {
+	t.Parallel()
+
+	expected := "json"
+	argv := []string{
+		"lorem",
+		"ipsum",
+		"--output=" + expected,
+	}
+	actual := getOutputFlag(argv)
+	assert.Equal(t, actual, expected, "output-flag")
+
+	argv = []string{
+		"lorem",
+		"--output",
+		expected,
+		"ipsum",
+	}
+	actual = getOutputFlag(argv)
+	assert.Equal(t, actual, expected, "output-flag")
+
+	actual = getOutputFlag([]string{"lorem", "ipsum"})
+	assert.Equal(t, actual, "", "output-flag")
+
+	actual = getOutputFlag([]string{"run", "--", "jq", "--output", "raw"})
+	assert.Equal(t, actual, "", "output-flag")
+}
+
+func TestRemoveOutputFlag(t *testing.T) {
+	t.Parallel()
+	expected := []string{
+		"lorem",
+		"ipsum",
+	}
+
+	argv := []string{
+		"lorem",
+		"ipsum",
+		"--output=yaml",
+	}
+	actual := removeOutputFlag(argv)
+	assert.Equal(t, actual, expected, "args")
+
+	argv = []string{
+		"lorem",
+		"--output",
+		"yaml",
+		"ipsum",
+	}
+	actual = removeOutputFlag(argv)
+	assert.Equal(t, actual, expected, "args")
+
+	argv = []string{"run", "--", "jq", "--output=raw"}
+	actual = removeOutputFlag(argv)
+	assert.Equal(t, actual, argv, "args")
+}
+
+func TestGetDryRunFlag(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, getDryRunFlag([]string{"config:set", "--dry-run", "FOO=bar"}), true, "dry run")
+	assert.Equal(t, getDryRunFlag([]string{"config:set", "FOO=bar"}), false, "dry run")
+	assert.Equal(t, getDryRunFlag([]string{"run", "--", "rake", "--dry-run"}), false, "dry run")
+}
+
+func TestRemoveDryRunFlag(t *testing.T) {
+	t.Parallel()
+
+	actual := removeDryRunFlag([]string{"config:set", "--dry-run", "FOO=bar"})
+	assert.Equal(t, actual, []string{"config:set", "FOO=bar"}, "args")
+
+	actual = removeDryRunFlag([]string{"run", "--", "rake", "--dry-run"})
+	assert.Equal(t, actual, []string{"run", "--", "rake", "--dry-run"}, "args")
+}
@@ -3,6 +3,7 @@ package parser
 import (
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/deis/workflow-cli/cmd"
 	docopt "github.com/docopt/docopt-go"
@@ -21,6 +22,9 @@ apps:logs          view aggregated application logs
 apps:run           run a command in an ephemeral app container
 apps:destroy       destroy an application
 apps:transfer      transfer app ownership to another user
+apps:apply         apply a manifest to an application
+apps:export        export an application's settings as a manifest
+apps:clone         copy an application's settings to a new application
 
 Use 'deis help [command]' to learn more.
 `
@@ -42,6 +46,12 @@ Use 'deis help [command]' to learn more.
 		return appDestroy(argv, cmdr)
 	case "apps:transfer":
 		return appTransfer(argv, cmdr)
+	case "apps:apply":
+		return appApply(argv, cmdr)
+	case "apps:export":
+		return appExport(argv, cmdr)
+	case "apps:clone":
+		return appClone(argv, cmdr)
 	default:
 		if printHelp(argv, usage) {
 			return nil
@@ -175,9 +185,20 @@ Options:
   -n --lines=<lines>
     the number of lines to display
   -p --process=<process>
-    the process type to show(Works only with follow)
+    only show the logs of the given process types, separated by commas, such as web,worker.
   -f --follow
-    tail log.
+    tail log, reconnecting if the connection drops.
+  --grep=<pattern>
+    only show the log lines matching a regular expression.
+  --since=<time>
+    only show the log lines after a time, such as 2017-03-10T16:00:00Z, or a duration
+    ago, such as 30m.
+  --until=<time>
+    only show the log lines before a time, such as 2017-03-10T17:00:00Z, or a duration
+    ago, such as 10m. Following the logs stops once this time is reached.
+  --json
+    print each log line as a JSON record, with its timestamp, source, process type, pod
+    and message.
 `
 
 	args, err := docopt.Parse(usage, argv, true, "", false, true)
@@ -190,7 +211,10 @@ Options:
 
 	tail := args["--follow"].(bool)
 
-	process := safeGetValue(args, "--process")
+	var processes []string
+	if process := safeGetValue(args, "--process"); process != "" {
+		processes = strings.Split(process, ",")
+	}
 
 	linesStr := safeGetValue(args, "--lines")
 	var lines int
@@ -205,14 +229,29 @@ Options:
 		}
 	}
 
-	return cmdr.AppLogs(app, lines, process, tail)
+	now := time.Now()
+
+	since, err := timeFromString(safeGetValue(args, "--since"), now)
+	if err != nil {
+		return err
+	}
+
+	until, err := timeFromString(safeGetValue(args, "--until"), now)
+	if err != nil {
+		return err
+	}
 
+	return cmdr.AppLogs(app, lines, processes, tail, safeGetValue(args, "--grep"), since, until,
+		args["--json"].(bool))
 }
 
 func appRun(argv []string, cmdr cmd.Commander) error {
 	usage := `
 Runs a command inside an ephemeral app container. Default environment is
-/bin/bash.
+/bin/bash. Its output is streamed as it runs, and the exit code of the command
+is the exit code of deis. Pressing Ctrl-C cancels the command. With --tty, the
+command gets a terminal and its input is streamed too, so it can be
+interactive, such as a shell or a console.
 
 Usage: deis apps:run [options] [--] <command>...
 
@@ -223,6 +262,10 @@ Arguments:
 Options:
   -a --app=<app>
     the uniquely identifiable name for the application.
+  -t --tty
+    allocate a terminal for the command and stream its input and output.
+  --timeout=<duration>
+    cancel the command if it's still running after this long, such as 10m.
 `
 
 	args, err := docopt.Parse(usage, argv, true, "", false, true)
@@ -234,7 +277,14 @@ Options:
 	app := safeGetValue(args, "--app")
 	command := strings.Join(args["<command>"].([]string), " ")
 
-	return cmdr.AppRun(app, command)
+	var timeout time.Duration
+	if value := safeGetValue(args, "--timeout"); value != "" {
+		if timeout, err = time.ParseDuration(value); err != nil {
+			return err
+		}
+	}
+
+	return cmdr.AppRun(app, command, args["--tty"].(bool), timeout)
 }
 
 func appDestroy(argv []string, cmdr cmd.Commander) error {
@@ -289,3 +339,124 @@ Options:
 
 	return cmdr.AppTransfer(app, user)
 }
+
+func appApply(argv []string, cmdr cmd.Commander) error {
+	usage := `
+Brings an application to the state described by a manifest file.
+
+The manifest is a YAML or JSON document. Sections left out of the manifest are not
+changed, sections that are present replace the application's current state, so keys
+missing from them are unset. The changes are listed before being applied.
+
+  app: myapp
+  config:
+    DATABASE_URL: postgres://db:5432/myapp
+  memory:
+    web: 512M
+  labels:
+    team: payments
+  autoscale:
+    web: {min: 2, max: 5, cpu_percent: 70}
+  domains:
+    - myapp.example.com
+  routable: true
+  https_enforced: true
+
+Supported sections: app, config, memory, cpu, healthchecks, tolerations, annotations,
+tags, labels, autoscale, whitelist, domains, certs, perms, routable, https_enforced and
+maintenance. Certificates listed under certs must already exist on the controller.
+
+Usage: deis apps:apply -f <file> [options]
+
+Options:
+  -f --file=<file>
+    the manifest to apply, or - to read it from stdin.
+  -a --app=<app>
+    the uniquely identifiable name for the application. Overrides the app in the manifest.
+  --reveal
+    show the values of secret config keys, such as *_PASSWORD, *_TOKEN and SECRET*.
+`
+
+	args, err := docopt.Parse(usage, argv, true, "", false, true)
+
+	if err != nil {
+		return err
+	}
+
+	app := safeGetValue(args, "--app")
+	file := safeGetValue(args, "--file")
+
+	return cmdr.AppApply(app, file, args["--reveal"].(bool))
+}
+
+func appExport(argv []string, cmdr cmd.Commander) error {
+	usage := `
+Exports the live state of an application as a manifest that can be passed to apps:apply.
+
+The manifest is written as YAML, or as JSON when --output=json is given. Config values
+are included as they are, so treat the manifest as a secret.
+
+Usage: deis apps:export [options]
+
+Options:
+  -a --app=<app>
+    the uniquely identifiable name for the application.
+  -f --file=<file>
+    write the manifest to a file instead of stdout.
+`
+
+	args, err := docopt.Parse(usage, argv, true, "", false, true)
+
+	if err != nil {
+		return err
+	}
+
+	app := safeGetValue(args, "--app")
+	file := safeGetValue(args, "--file")
+
+	return cmdr.AppExport(app, file)
+}
+
+func appClone(argv []string, cmdr cmd.Commander) error {
+	usage := `
+Creates a new application with the settings of an existing one: config, limits,
+healthchecks, tolerations, annotations, tags, labels, autoscale rules, whitelist, domains,
+TLS, routing and maintenance settings. Collaborators and certificates are copied too,
+unless the new application is created on another controller.
+
+Domains can only belong to one application per controller, so --exclude-domains is needed
+to clone an application with custom domains on the same controller.
+
+Usage: deis apps:clone --to=<app> [options]
+
+Options:
+  --from=<app>
+    the application to copy. Defaults to the application of the current directory.
+  --to=<app>
+    the name of the new application.
+  --target-profile=<profile>
+    create the new application on the controller of another profile.
+  --exclude-secrets
+    don't copy config values whose keys look like secrets (*_PASSWORD, *_TOKEN, SECRET*
+    and the secret_patterns of the settings file).
+  --exclude-domains
+    don't copy custom domains and certificates.
+  --deploy
+    deploy the image of the source application's current release to the new application.
+`
+
+	args, err := docopt.Parse(usage, argv, true, "", false, true)
+
+	if err != nil {
+		return err
+	}
+
+	from := safeGetValue(args, "--from")
+	to := safeGetValue(args, "--to")
+	targetProfile := safeGetValue(args, "--target-profile")
+	excludeSecrets := args["--exclude-secrets"].(bool)
+	excludeDomains := args["--exclude-domains"].(bool)
+	deploy := args["--deploy"].(bool)
+
+	return cmdr.AppClone(from, to, targetProfile, excludeSecrets, excludeDomains, deploy)
+}
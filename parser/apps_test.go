@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/arschles/assert"
 	"github.com/deis/workflow-cli/pkg/testutil"
@@ -28,11 +29,11 @@ func (d FakeDeisCmd) AppOpen(string) error {
 	return errors.New("apps:open")
 }
 
-func (d FakeDeisCmd) AppLogs(string, int, string, bool) error {
+func (d FakeDeisCmd) AppLogs(string, int, []string, bool, string, time.Time, time.Time, bool) error {
 	return errors.New("apps:logs")
 }
 
-func (d FakeDeisCmd) AppRun(string, string) error {
+func (d FakeDeisCmd) AppRun(string, string, bool, time.Duration) error {
 	return errors.New("apps:run")
 }
 
@@ -44,6 +45,18 @@ func (d FakeDeisCmd) AppTransfer(string, string) error {
 	return errors.New("apps:transfer")
 }
 
+func (d FakeDeisCmd) AppApply(string, string, bool) error {
+	return errors.New("apps:apply")
+}
+
+func (d FakeDeisCmd) AppExport(string, string) error {
+	return errors.New("apps:export")
+}
+
+func (d FakeDeisCmd) AppClone(string, string, string, bool, bool, bool) error {
+	return errors.New("apps:clone")
+}
+
 func TestApps(t *testing.T) {
 	t.Parallel()
 
@@ -85,10 +98,26 @@ func TestApps(t *testing.T) {
 			args:     []string{"apps:logs", "--lines=1"},
 			expected: "",
 		},
+		{
+			args:     []string{"apps:logs", "--process=web,worker", "--grep=error", "--since=30m", "--json"},
+			expected: "",
+		},
 		{
 			args:     []string{"apps:run", "ls"},
 			expected: "",
 		},
+		{
+			args:     []string{"apps:run", "--tty", "bash"},
+			expected: "",
+		},
+		{
+			args:     []string{"apps:run", "--timeout=10m", "rake", "db:migrate"},
+			expected: "",
+		},
+		{
+			args:     []string{"apps:run", "--timeout=later", "ls"},
+			expected: "time: invalid duration \"later\"",
+		},
 		{
 			args:     []string{"apps:destroy"},
 			expected: "",
@@ -97,6 +126,18 @@ func TestApps(t *testing.T) {
 			args:     []string{"apps:transfer", "test-user"},
 			expected: "",
 		},
+		{
+			args:     []string{"apps:apply", "-f", "app.yaml"},
+			expected: "",
+		},
+		{
+			args:     []string{"apps:export"},
+			expected: "",
+		},
+		{
+			args:     []string{"apps:clone", "--from=foo", "--to=bar", "--exclude-secrets"},
+			expected: "",
+		},
 		{
 			args:     []string{"apps"},
 			expected: "apps:list",
@@ -117,6 +117,26 @@ Options:
     provide a password for the account.
   --ssl-verify=true
     enables/disables SSL certificate verification for API requests
+  --ca-file=<file>
+    a PEM file of CA certificates to verify the controller's certificate with, along with
+    the system's. Kept for later logins to the same controller.
+  --client-cert=<file>
+    a PEM client certificate to authenticate to the controller with, for mutual TLS.
+  --client-key=<file>
+    the PEM private key of the client certificate.
+  --sso
+    log in through the single sign-on provider of the controller. It shows a code to approve
+    the login with, and opens the page to enter it at in a browser.
+  --sso-issuer=<url>
+    the OpenID Connect issuer URL of the single sign-on provider, if the controller doesn't
+    tell which one to use.
+  --sso-client-id=<id>
+    the client ID of the CLI at the single sign-on provider, if the controller doesn't tell.
+  --credential-store=<store>
+    where to keep the token: file, the settings file, which is the default; encrypted-file,
+    a file encrypted with a passphrase from $DEIS_CREDENTIAL_PASSPHRASE or a prompt; or the
+    name of a credential helper, a deis-credential-<store> program on the PATH that speaks
+    the protocol of docker's credential helpers.
 `
 
 	args, err := docopt.Parse(usage, argv, true, "", false, true)
@@ -128,6 +148,10 @@ Options:
 	controller := safeGetValue(args, "<controller>")
 	username := safeGetValue(args, "--username")
 	password := safeGetValue(args, "--password")
+	credentialStore := safeGetValue(args, "--credential-store")
+	caFile := safeGetValue(args, "--ca-file")
+	clientCert := safeGetValue(args, "--client-cert")
+	clientKey := safeGetValue(args, "--client-key")
 	googleAuth := true
 	sslVerify := true
 
@@ -135,11 +159,17 @@ Options:
 		sslVerify = false
 	}
 
+	if args["--sso"].(bool) {
+		return cmdr.LoginSSO(controller, safeGetValue(args, "--sso-issuer"),
+			safeGetValue(args, "--sso-client-id"), sslVerify, credentialStore, caFile, clientCert, clientKey)
+	}
+
 	if args["--google-auth"] != nil && args["--google-auth"].(string) == "false" {
 		googleAuth = false
 	}
 
-	return cmdr.Login(controller, username, password, sslVerify, googleAuth)
+	return cmdr.Login(controller, username, password, sslVerify, googleAuth, credentialStore, caFile,
+		clientCert, clientKey)
 }
 
 func authLogout(argv []string, cmdr cmd.Commander) error {
@@ -188,7 +218,8 @@ Options:
 
 func authWhoami(argv []string, cmdr cmd.Commander) error {
 	usage := `
-Displays the currently logged in user.
+Displays the currently logged in user, and where the settings used to log in came from.
+Flags given to a command override environment variables, which override the settings file.
 
 Usage: deis auth:whoami [options]
 
@@ -16,10 +16,14 @@ func (d FakeDeisCmd) Register(string, string, string, string, bool, bool) error
 	return errors.New("auth:register")
 }
 
-func (d FakeDeisCmd) Login(string, string, string, bool, bool) error {
+func (d FakeDeisCmd) Login(string, string, string, bool, bool, string, string, string, string) error {
 	return errors.New("auth:login")
 }
 
+func (d FakeDeisCmd) LoginSSO(string, string, string, bool, string, string, string, string) error {
+	return errors.New("auth:login --sso")
+}
+
 func (d FakeDeisCmd) Logout() error {
 	return errors.New("auth:logout")
 }
@@ -77,6 +81,10 @@ func TestAuth(t *testing.T) {
 			args:     []string{"auth:login", server.Server.URL, "--ssl-verify=true"},
 			expected: "",
 		},
+		{
+			args:     []string{"auth:login", server.Server.URL, "--sso", "--sso-client-id=deis"},
+			expected: "auth:login --sso",
+		},
 		{
 			args:     []string{"auth:logout"},
 			expected: "",
@@ -11,7 +11,9 @@ func Builds(argv []string, cmdr cmd.Commander) error {
 Valid commands for builds:
 
 builds:list        list build history for an application
+builds:info        print the details of a build and the releases that used it
 builds:create      imports an image and deploys as a new release
+builds:validate    check a Procfile and Sidecarfile without creating a build
 
 Use 'deis help [command]' to learn more.
 `
@@ -19,8 +21,12 @@ Use 'deis help [command]' to learn more.
 	switch argv[0] {
 	case "builds:list":
 		return buildsList(argv, cmdr)
+	case "builds:info":
+		return buildsInfo(argv, cmdr)
 	case "builds:create":
 		return buildsCreate(argv, cmdr)
+	case "builds:validate":
+		return buildsValidate(argv, cmdr)
 	default:
 		if printHelp(argv, usage) {
 			return nil
@@ -38,7 +44,9 @@ Use 'deis help [command]' to learn more.
 
 func buildsList(argv []string, cmdr cmd.Commander) error {
 	usage := `
-Lists build history for an application.
+Lists build history for an application, with the image, owner, git SHA, process
+types and sidecars of each build, whether it was built from a Dockerfile, and the
+releases that used it.
 
 Usage: deis builds:list [options]
 
@@ -64,12 +72,38 @@ Options:
 	return cmdr.BuildsList(safeGetValue(args, "--app"), results)
 }
 
+func buildsInfo(argv []string, cmdr cmd.Commander) error {
+	usage := `
+Prints the details of a build: its image, owner, git SHA, Procfile and Sidecarfile,
+and the releases that used it.
+
+Usage: deis builds:info <uuid> [options]
+
+Arguments:
+  <uuid>
+    the UUID of the build, as listed by 'deis builds:list'.
+
+Options:
+  -a --app=<app>
+    the uniquely identifiable name for the application.
+`
+
+	args, err := docopt.Parse(usage, argv, true, "", false, true)
+
+	if err != nil {
+		return err
+	}
+
+	return cmdr.BuildsInfo(safeGetValue(args, "--app"), safeGetValue(args, "<uuid>"))
+}
+
 func buildsCreate(argv []string, cmdr cmd.Commander) error {
 	usage := `
 Creates a new build of an application. Imports an <image> and deploys it to Deis
 as a new release. If a Procfile is present in the current directory, it will be used
 as the default process types for this application. If a Sidecarfile is present in the
 current directory, it will be used to deploy sidecars to the defined process types.
+Both are checked first, as 'deis builds:validate' does.
 
 Usage: deis builds:create <image> [options]
 
@@ -86,6 +120,10 @@ Options:
     A YAML string used to supply a Procfile to the application.
   -s --sidecarfile=<sidecarfile>
     A YAML string used to supply a Sidecarfile to the application.
+  --wait
+    wait for the processes to be up on the new release, failing if any is crashing.
+  --timeout=<duration>
+    how long --wait waits, such as 90s or 10m. [default: 5m]
 `
 
 	args, err := docopt.Parse(usage, argv, true, "", false, true)
@@ -99,5 +137,35 @@ Options:
 	procfile := safeGetValue(args, "--procfile")
 	sidecarfile := safeGetValue(args, "--sidecarfile")
 
-	return cmdr.BuildsCreate(app, image, procfile, sidecarfile)
+	wait, err := waitTimeout(args)
+	if err != nil {
+		return err
+	}
+
+	return cmdr.BuildsCreate(app, image, procfile, sidecarfile, wait)
+}
+
+func buildsValidate(argv []string, cmdr cmd.Commander) error {
+	usage := `
+Checks a Procfile and a Sidecarfile without creating a build, or contacting the
+controller. Process types must be lowercase letters, numbers and dashes, such as web
+or background-worker, and commands can't be empty. Sidecars must be lists of
+container specs with a name and an image, for process types in the Procfile.
+
+Usage: deis builds:validate [options]
+
+Options:
+  -p --procfile=<procfile>
+    A YAML string to check instead of the Procfile in the current directory.
+  -s --sidecarfile=<sidecarfile>
+    A YAML string to check instead of the Sidecarfile in the current directory.
+`
+
+	args, err := docopt.Parse(usage, argv, true, "", false, true)
+
+	if err != nil {
+		return err
+	}
+
+	return cmdr.BuildsValidate(safeGetValue(args, "--procfile"), safeGetValue(args, "--sidecarfile"))
 }
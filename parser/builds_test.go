@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/arschles/assert"
 	"github.com/deis/workflow-cli/pkg/testutil"
@@ -13,10 +14,18 @@ func (d FakeDeisCmd) BuildsList(string, int) error {
 	return errors.New("builds:list")
 }
 
-func (d FakeDeisCmd) BuildsCreate(string, string, string, string) error {
+func (d FakeDeisCmd) BuildsInfo(string, string) error {
+	return errors.New("builds:info")
+}
+
+func (d FakeDeisCmd) BuildsCreate(string, string, string, string, time.Duration) error {
 	return errors.New("builds:create")
 }
 
+func (d FakeDeisCmd) BuildsValidate(string, string) error {
+	return errors.New("builds:validate")
+}
+
 func TestBuilds(t *testing.T) {
 	t.Parallel()
 
@@ -38,10 +47,18 @@ func TestBuilds(t *testing.T) {
 			args:     []string{"builds:list"},
 			expected: "",
 		},
+		{
+			args:     []string{"builds:info", "de1bf5b5-4a72-4f94-a10c-d2a3741cdf75"},
+			expected: "",
+		},
 		{
 			args:     []string{"builds:create", "deis/example-go:latest"},
 			expected: "",
 		},
+		{
+			args:     []string{"builds:validate", "--procfile=web: ./server"},
+			expected: "",
+		},
 		{
 			args:     []string{"builds"},
 			expected: "builds:list",
@@ -15,6 +15,7 @@ config:set         set environment variables for an app
 config:unset       unset environment variables for an app
 config:pull        extract environment variables to .env
 config:push        set environment variables from .env
+config:diff        compare environment variables with an app, release or file
 
 Use 'deis help [command]' to learn more.
 `
@@ -30,6 +31,8 @@ Use 'deis help [command]' to learn more.
 		return configPull(argv, cmdr)
 	case "config:push":
 		return configPush(argv, cmdr)
+	case "config:diff":
+		return configDiff(argv, cmdr)
 	default:
 		if printHelp(argv, usage) {
 			return nil
@@ -56,6 +59,8 @@ Options:
     print output on one line.
   --diff
     print output on multiple lines for comparison against .env files.
+  --reveal
+    show the values of secret keys, such as *_PASSWORD, *_TOKEN and SECRET*.
   -a --app=<app>
     the uniquely identifiable name of the application.
 `
@@ -76,7 +81,7 @@ Options:
 		format = "diff"
 	}
 
-	return cmdr.ConfigList(app, format)
+	return cmdr.ConfigList(app, format, args["--reveal"].(bool))
 }
 
 func configSet(argv []string, cmdr cmd.Commander) error {
@@ -94,6 +99,12 @@ Arguments:
 Options:
   -a --app=<app>
     the uniquely identifiable name for the application.
+  --reveal
+    show the values of secret keys, such as *_PASSWORD, *_TOKEN and SECRET*.
+  --wait
+    wait for the processes to be up on the new release, failing if any is crashing.
+  --timeout=<duration>
+    how long --wait waits, such as 90s or 10m. [default: 5m]
 `
 
 	args, err := docopt.Parse(usage, argv, true, "", false, true)
@@ -104,7 +115,12 @@ Options:
 
 	app := safeGetValue(args, "--app")
 
-	return cmdr.ConfigSet(app, args["<var>=<value>"].([]string))
+	wait, err := waitTimeout(args)
+	if err != nil {
+		return err
+	}
+
+	return cmdr.ConfigSet(app, args["<var>=<value>"].([]string), args["--reveal"].(bool), wait)
 }
 
 func configUnset(argv []string, cmdr cmd.Commander) error {
@@ -120,6 +136,8 @@ Arguments:
 Options:
   -a --app=<app>
     the uniquely identifiable name for the application.
+  --reveal
+    show the values of secret keys, such as *_PASSWORD, *_TOKEN and SECRET*.
 `
 
 	args, err := docopt.Parse(usage, argv, true, "", false, true)
@@ -129,7 +147,7 @@ Options:
 	}
 	app := safeGetValue(args, "--app")
 
-	return cmdr.ConfigUnset(app, args["<key>"].([]string))
+	return cmdr.ConfigUnset(app, args["<key>"].([]string), args["--reveal"].(bool))
 }
 
 func configPull(argv []string, cmdr cmd.Commander) error {
@@ -172,6 +190,9 @@ This file can be read by foreman
 to load the local environment for your app. The file should be piped via
 stdin, 'deis config:push < .env', or using the --path option.
 
+Lines may start with 'export' and end with a '#' comment. Values may be single quoted,
+or double quoted with \n, \", \\ and \$ escapes, and quoted values may span several lines.
+
 Usage: deis config:push [options]
 
 Options:
@@ -192,3 +213,55 @@ Options:
 
 	return cmdr.ConfigPush(app, path)
 }
+
+func configDiff(argv []string, cmdr cmd.Commander) error {
+	usage := `
+Compares the environment variables of an application with those of another application,
+of a release, or of a local environment file.
+
+Keys only set on the application are prefixed with '+', keys it is missing with '-' and
+keys with a different value with '~'. The command exits with status 1 if there are any
+differences, so it can be used to detect drift in scripts.
+
+The controller only keeps an application's current environment, so comparing with a
+release lists the releases that changed the environment since then, and exits with status 0
+as the values can't be compared.
+
+Usage: deis config:diff (--with-app=<app> | --with-release=<version> | --with-file=<path>) [options]
+
+Options:
+  -a --app=<app>
+    the uniquely identifiable name for the application.
+  --with-app=<app>
+    the application to compare with.
+  --with-release=<version>
+    the release to compare with, in the form v#.
+  --with-file=<path>
+    the environment file to compare with, such as .env.
+  --mask
+    hide values, only showing which keys differ.
+  --reveal
+    show the values of secret keys, such as *_PASSWORD, *_TOKEN and SECRET*.
+`
+
+	args, err := docopt.Parse(usage, argv, true, "", false, true)
+
+	if err != nil {
+		return err
+	}
+
+	app := safeGetValue(args, "--app")
+	otherApp := safeGetValue(args, "--with-app")
+	file := safeGetValue(args, "--with-file")
+	mask := args["--mask"].(bool)
+	reveal := args["--reveal"].(bool)
+
+	version := -1
+	if release := safeGetValue(args, "--with-release"); release != "" {
+		if version, err = versionFromString(release); err != nil {
+			return err
+		}
+	}
+
+	return cmdr.ConfigDiff(app, otherApp, version, file, mask, reveal)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/arschles/assert"
 	"github.com/deis/workflow-cli/pkg/testutil"
@@ -12,15 +13,15 @@ import (
 // Create fake implementations of each method that return the argument
 // we expect to have called the function (as an error to satisfy the interface).
 
-func (d FakeDeisCmd) ConfigList(string, string) error {
+func (d FakeDeisCmd) ConfigList(string, string, bool) error {
 	return errors.New("config:list")
 }
 
-func (d FakeDeisCmd) ConfigSet(string, []string) error {
+func (d FakeDeisCmd) ConfigSet(string, []string, bool, time.Duration) error {
 	return errors.New("config:set")
 }
 
-func (d FakeDeisCmd) ConfigUnset(string, []string) error {
+func (d FakeDeisCmd) ConfigUnset(string, []string, bool) error {
 	return errors.New("config:unset")
 }
 
@@ -32,6 +33,10 @@ func (d FakeDeisCmd) ConfigPush(string, string) error {
 	return errors.New("config:push")
 }
 
+func (d FakeDeisCmd) ConfigDiff(string, string, int, string, bool, bool) error {
+	return errors.New("config:diff")
+}
+
 func TestConfig(t *testing.T) {
 	t.Parallel()
 
@@ -69,6 +74,14 @@ func TestConfig(t *testing.T) {
 			args:     []string{"config:push"},
 			expected: "",
 		},
+		{
+			args:     []string{"config:diff", "--with-release=v12"},
+			expected: "",
+		},
+		{
+			args:     []string{"config:diff", "--with-file=.env", "--mask"},
+			expected: "",
+		},
 		{
 			args:     []string{"config"},
 			expected: "config:list",
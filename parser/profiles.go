@@ -0,0 +1,142 @@
+package parser
+
+import (
+	"github.com/deis/workflow-cli/cmd"
+	docopt "github.com/docopt/docopt-go"
+)
+
+// Profiles routes profile commands to their specific function.
+func Profiles(argv []string, cmdr cmd.Commander) error {
+	usage := `
+Valid commands for profiles:
+
+profiles:list      list the client profiles stored in ~/.deis
+profiles:use       select the profile used by default
+profiles:show      view the details of a profile
+profiles:rename    rename a profile
+profiles:delete    delete a profile
+
+Use 'deis help [command]' to learn more.
+`
+
+	switch argv[0] {
+	case "profiles:list":
+		return profilesList(argv, cmdr)
+	case "profiles:use":
+		return profilesUse(argv, cmdr)
+	case "profiles:show":
+		return profilesShow(argv, cmdr)
+	case "profiles:rename":
+		return profilesRename(argv, cmdr)
+	case "profiles:delete":
+		return profilesDelete(argv, cmdr)
+	default:
+		if printHelp(argv, usage) {
+			return nil
+		}
+
+		if argv[0] == "profiles" {
+			argv[0] = "profiles:list"
+			return profilesList(argv, cmdr)
+		}
+
+		PrintUsage(cmdr)
+		return nil
+	}
+}
+
+func profilesList(argv []string, cmdr cmd.Commander) error {
+	usage := `
+Lists the client profiles stored in ~/.deis. The active profile is marked with '*'.
+
+Usage: deis profiles:list
+`
+
+	if _, err := docopt.Parse(usage, argv, true, "", false, true); err != nil {
+		return err
+	}
+
+	return cmdr.ProfilesList()
+}
+
+func profilesUse(argv []string, cmdr cmd.Commander) error {
+	usage := `
+Selects the profile used when neither --config nor $DEIS_PROFILE is given.
+
+Usage: deis profiles:use <name>
+
+Arguments:
+  <name>
+    the name of the profile, as shown by 'deis profiles:list'.
+`
+
+	args, err := docopt.Parse(usage, argv, true, "", false, true)
+
+	if err != nil {
+		return err
+	}
+
+	return cmdr.ProfilesUse(safeGetValue(args, "<name>"))
+}
+
+func profilesShow(argv []string, cmdr cmd.Commander) error {
+	usage := `
+Shows the controller, username and SSL verification setting of a profile.
+
+Usage: deis profiles:show [<name>]
+
+Arguments:
+  <name>
+    the name of the profile. Defaults to the active profile.
+`
+
+	args, err := docopt.Parse(usage, argv, true, "", false, true)
+
+	if err != nil {
+		return err
+	}
+
+	return cmdr.ProfilesShow(safeGetValue(args, "<name>"))
+}
+
+func profilesRename(argv []string, cmdr cmd.Commander) error {
+	usage := `
+Renames a profile.
+
+Usage: deis profiles:rename <name> <new-name>
+
+Arguments:
+  <name>
+    the current name of the profile.
+  <new-name>
+    the new name of the profile.
+`
+
+	args, err := docopt.Parse(usage, argv, true, "", false, true)
+
+	if err != nil {
+		return err
+	}
+
+	return cmdr.ProfilesRename(safeGetValue(args, "<name>"), safeGetValue(args, "<new-name>"))
+}
+
+func profilesDelete(argv []string, cmdr cmd.Commander) error {
+	usage := `
+Deletes a profile and the credentials stored in it.
+
+Usage: deis profiles:delete <name>
+
+Arguments:
+  <name>
+    the name of the profile to delete.
+`
+
+	args, err := docopt.Parse(usage, argv, true, "", false, true)
+
+	if err != nil {
+		return err
+	}
+
+	return cmdr.ProfilesDelete(safeGetValue(args, "<name>"))
+}
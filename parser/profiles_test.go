@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/arschles/assert"
+	"github.com/deis/workflow-cli/pkg/testutil"
+)
+
+// Create fake implementations of each method that return the argument
+// we expect to have called the function (as an error to satisfy the interface).
+
+func (d FakeDeisCmd) ProfilesList() error {
+	return errors.New("profiles:list")
+}
+
+func (d FakeDeisCmd) ProfilesUse(string) error {
+	return errors.New("profiles:use")
+}
+
+func (d FakeDeisCmd) ProfilesShow(string) error {
+	return errors.New("profiles:show")
+}
+
+func (d FakeDeisCmd) ProfilesRename(string, string) error {
+	return errors.New("profiles:rename")
+}
+
+func (d FakeDeisCmd) ProfilesDelete(string) error {
+	return errors.New("profiles:delete")
+}
+
+func TestProfiles(t *testing.T) {
+	t.Parallel()
+
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	var b bytes.Buffer
+	cmdr := FakeDeisCmd{WOut: &b, ConfigFile: cf}
+
+	// cases defines the arguments and expected return of the call.
+	// if expected is "", it defaults to args[0].
+	cases := []struct {
+		args     []string
+		expected string
+	}{
+		{
+			args:     []string{"profiles:list"},
+			expected: "",
+		},
+		{
+			args:     []string{"profiles:use", "staging"},
+			expected: "",
+		},
+		{
+			args:     []string{"profiles:show"},
+			expected: "",
+		},
+		{
+			args:     []string{"profiles:show", "staging"},
+			expected: "profiles:show",
+		},
+		{
+			args:     []string{"profiles:rename", "staging", "prod-us"},
+			expected: "",
+		},
+		{
+			args:     []string{"profiles:delete", "staging"},
+			expected: "",
+		},
+		{
+			args:     []string{"profiles"},
+			expected: "profiles:list",
+		},
+	}
+
+	// For each case, check that calling the route with the arguments
+	// returns the expected error, which is args[0] if not provided.
+	for _, c := range cases {
+		var expected string
+		if c.expected == "" {
+			expected = c.args[0]
+		} else {
+			expected = c.expected
+		}
+		err = Profiles(c.args, cmdr)
+		assert.Err(t, errors.New(expected), err)
+	}
+}
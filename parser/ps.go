@@ -1,6 +1,9 @@
 package parser
 
 import (
+	"strconv"
+	"time"
+
 	"github.com/deis/workflow-cli/cmd"
 	docopt "github.com/docopt/docopt-go"
 )
@@ -13,6 +16,9 @@ Valid commands for processes:
 ps:list        list application processes
 ps:restart     restart an application or its process types
 ps:scale       scale processes (e.g. web=4 worker=2)
+ps:wait        wait for processes to be up on the latest release
+ps:describe    explain why a process is not running
+ps:exec        run a command in a running process
 
 Use 'deis help [command]' to learn more.
 `
@@ -24,6 +30,12 @@ Use 'deis help [command]' to learn more.
 		return psRestart(argv, cmdr)
 	case "ps:scale":
 		return psScale(argv, cmdr)
+	case "ps:wait":
+		return psWait(argv, cmdr)
+	case "ps:describe":
+		return psDescribe(argv, cmdr)
+	case "ps:exec":
+		return psExec(argv, cmdr)
 	default:
 		if printHelp(argv, usage) {
 			return nil
@@ -41,13 +53,21 @@ Use 'deis help [command]' to learn more.
 
 func psList(argv []string, cmdr cmd.Commander) error {
 	usage := `
-Lists processes servicing an application.
+Lists processes servicing an application, with their type, state, release and age.
 
 Usage: deis ps:list [options]
 
 Options:
   -a --app=<app>
     the uniquely identifiable name for the application.
+  --type=<type>
+    only list the processes of a type, such as 'web' or 'worker'.
+  --state=<state>
+    only list the processes in a state, such as 'up', 'starting' or 'crashed'.
+  --release=<version>
+    only list the processes running a release, such as 'v3'.
+  -w --watch
+    keep listing the processes, redrawing the list whenever one changes.
 `
 
 	args, err := docopt.Parse(usage, argv, true, "", false, true)
@@ -56,7 +76,9 @@ Options:
 	}
 
 	// The 1000 is fake for now until API understands limits
-	return cmdr.PsList(safeGetValue(args, "--app"), 1000)
+	return cmdr.PsList(safeGetValue(args, "--app"), 1000, safeGetValue(args, "--type"),
+		safeGetValue(args, "--state"), safeGetValue(args, "--release"), args["--watch"].(bool),
+		time.Now())
 }
 
 func psRestart(argv []string, cmdr cmd.Commander) error {
@@ -73,6 +95,10 @@ Arguments:
 Options:
   -a --app=<app>
     the uniquely identifiable name for the application.
+  --wait
+    wait for the processes to be up on the new release, failing if any is crashing.
+  --timeout=<duration>
+    how long --wait waits, such as 90s or 10m. [default: 5m]
 `
 
 	args, err := docopt.Parse(usage, argv, true, "", false, true)
@@ -81,9 +107,14 @@ Options:
 		return err
 	}
 
+	wait, err := waitTimeout(args)
+	if err != nil {
+		return err
+	}
+
 	apps := safeGetValue(args, "--app")
 	tp := safeGetValue(args, "<type>")
-	return cmdr.PsRestart(apps, tp)
+	return cmdr.PsRestart(apps, tp, wait)
 }
 
 func psScale(argv []string, cmdr cmd.Commander) error {
@@ -102,6 +133,10 @@ Arguments:
 Options:
   -a --app=<app>
     the uniquely identifiable name for the application.
+  --wait
+    wait for the processes to be up on the new release, failing if any is crashing.
+  --timeout=<duration>
+    how long --wait waits, such as 90s or 10m. [default: 5m]
 `
 
 	args, err := docopt.Parse(usage, argv, true, "", false, true)
@@ -110,6 +145,108 @@ Options:
 		return err
 	}
 
+	wait, err := waitTimeout(args)
+	if err != nil {
+		return err
+	}
+
 	apps := safeGetValue(args, "--app")
-	return cmdr.PsScale(apps, args["<type>=<num>"].([]string))
+	return cmdr.PsScale(apps, args["<type>=<num>"].([]string), wait)
+}
+
+func psWait(argv []string, cmdr cmd.Commander) error {
+	usage := `
+Waits until every process of the given types, or of all types, is up on the latest release
+of an application, printing the progress of each type. Exits with an error if a process is
+crashing or the timeout expires, so it can be relied on by deploy pipelines.
+
+Usage: deis ps:wait [<type>...] [options]
+
+Arguments:
+  <type>
+    the process name as defined in your Procfile, such as 'web' or 'worker'.
+
+Options:
+  -a --app=<app>
+    the uniquely identifiable name for the application.
+  --timeout=<duration>
+    how long to wait, such as 90s or 10m. [default: 5m]
+`
+
+	args, err := docopt.Parse(usage, argv, true, "", false, true)
+
+	if err != nil {
+		return err
+	}
+
+	timeout, err := time.ParseDuration(safeGetValue(args, "--timeout"))
+	if err != nil {
+		return err
+	}
+
+	return cmdr.PsWait(safeGetValue(args, "--app"), args["<type>"].([]string), timeout)
+}
+
+func psDescribe(argv []string, cmdr cmd.Commander) error {
+	usage := `
+Describes a process: its state and release, the limits, healthchecks and tolerations of its
+type, and its recent log lines. Lists the likely causes of it crashing or not starting, such
+as a memory limit below its request or a readiness probe that hasn't passed yet, when its
+state or its logs point at them.
+
+Usage: deis ps:describe <process> [options]
+
+Arguments:
+  <process>
+    the name of the process, such as 'foo-web-4084101150-c871y'.
+
+Options:
+  -a --app=<app>
+    the uniquely identifiable name for the application.
+  -n --lines=<lines>
+    the number of recent log lines of the application to search. [default: 100]
+`
+
+	args, err := docopt.Parse(usage, argv, true, "", false, true)
+
+	if err != nil {
+		return err
+	}
+
+	lines, err := strconv.Atoi(safeGetValue(args, "--lines"))
+	if err != nil {
+		return err
+	}
+
+	return cmdr.PsDescribe(safeGetValue(args, "--app"), safeGetValue(args, "<process>"), lines)
+}
+
+func psExec(argv []string, cmdr cmd.Commander) error {
+	usage := `
+Runs a command in a running process, streaming its input and output. With --tty,
+the command gets a terminal, so it can be interactive, such as a shell.
+
+Usage: deis ps:exec <process> [options] [--] <command>...
+
+Arguments:
+  <process>
+    the name of the process, such as 'foo-web-4084101150-c871y'.
+  <command>
+    the command to run and its arguments, such as 'ls -la'.
+
+Options:
+  -a --app=<app>
+    the uniquely identifiable name for the application.
+  -t --tty
+    allocate a terminal for the command.
+`
+
+	args, err := docopt.Parse(usage, argv, true, "", false, true)
+
+	if err != nil {
+		return err
+	}
+
+	return cmdr.PsExec(safeGetValue(args, "--app"), safeGetValue(args, "<process>"),
+		args["<command>"].([]string), args["--tty"].(bool))
 }
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/arschles/assert"
 	"github.com/deis/workflow-cli/pkg/testutil"
@@ -12,18 +13,30 @@ import (
 // Create fake implementations of each method that return the argument
 // we expect to have called the function (as an error to satisfy the interface).
 
-func (d FakeDeisCmd) PsList(string, int) error {
+func (d FakeDeisCmd) PsList(string, int, string, string, string, bool, time.Time) error {
 	return errors.New("ps:list")
 }
 
-func (d FakeDeisCmd) PsScale(string, []string) error {
+func (d FakeDeisCmd) PsScale(string, []string, time.Duration) error {
 	return errors.New("ps:scale")
 }
 
-func (d FakeDeisCmd) PsRestart(string, string) error {
+func (d FakeDeisCmd) PsRestart(string, string, time.Duration) error {
 	return errors.New("ps:restart")
 }
 
+func (d FakeDeisCmd) PsWait(string, []string, time.Duration) error {
+	return errors.New("ps:wait")
+}
+
+func (d FakeDeisCmd) PsDescribe(string, string, int) error {
+	return errors.New("ps:describe")
+}
+
+func (d FakeDeisCmd) PsExec(string, string, []string, bool) error {
+	return errors.New("ps:exec")
+}
+
 func TestPs(t *testing.T) {
 	t.Parallel()
 
@@ -53,10 +66,34 @@ func TestPs(t *testing.T) {
 			args:     []string{"ps:scale", "web", "5"},
 			expected: "",
 		},
+		{
+			args:     []string{"ps:scale", "web=5", "--wait", "--timeout=90s"},
+			expected: "",
+		},
+		{
+			args:     []string{"ps:wait", "web", "worker"},
+			expected: "",
+		},
+		{
+			args:     []string{"ps:wait", "--timeout=soon"},
+			expected: "time: invalid duration \"soon\"",
+		},
 		{
 			args:     []string{"ps:list"},
 			expected: "",
 		},
+		{
+			args:     []string{"ps:list", "--type=web", "--state=up", "--release=v3", "--watch"},
+			expected: "",
+		},
+		{
+			args:     []string{"ps:describe", "foo-web-4084101150-c871y", "--lines=50"},
+			expected: "",
+		},
+		{
+			args:     []string{"ps:exec", "foo-web-4084101150-c871y", "--tty", "--", "ls", "-la"},
+			expected: "",
+		},
 		{
 			args:     []string{"ps"},
 			expected: "ps:list",
@@ -3,6 +3,7 @@ package parser
 import (
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/deis/workflow-cli/cmd"
 	docopt "github.com/docopt/docopt-go"
@@ -16,6 +17,7 @@ Valid commands for releases:
 releases:list        list an application's release history
 releases:info        print information about a specific release
 releases:rollback    return to a previous release
+releases:diff        show what changed between two releases
 
 Use 'deis help [command]' to learn more.
 `
@@ -27,6 +29,8 @@ Use 'deis help [command]' to learn more.
 		return releasesInfo(argv, cmdr)
 	case "releases:rollback":
 		return releasesRollback(argv, cmdr)
+	case "releases:diff":
+		return releasesDiff(argv, cmdr)
 	default:
 		if printHelp(argv, usage) {
 			return nil
@@ -44,7 +48,8 @@ Use 'deis help [command]' to learn more.
 
 func releasesList(argv []string, cmdr cmd.Commander) error {
 	usage := `
-Lists release history for an application.
+Lists release history for an application, newest first. Use 'deis --output=json
+releases:list' to print the releases as JSON.
 
 Usage: deis releases:list [options]
 
@@ -53,6 +58,19 @@ Options:
     the uniquely identifiable name for the application.
   -l --limit=<num>
     the maximum number of results to display, defaults to config setting
+  --owner=<username>
+    only list the releases made by a user.
+  --summary=<text>
+    only list the releases whose summary contains some text, such as 'deployed',
+    'added' or 'rolled back'.
+  --since=<time>
+    only list the releases created after a time, such as 2017-03-10, or a duration
+    ago, such as 168h.
+  --until=<time>
+    only list the releases created before a time, such as 2017-03-17T12:00:00Z, or a
+    duration ago, such as 24h.
+  --since-version=<version>
+    only list the releases after a release, such as 'v12'.
 `
 
 	args, err := docopt.Parse(usage, argv, true, "", false, true)
@@ -67,7 +85,27 @@ Options:
 
 	app := safeGetValue(args, "--app")
 
-	return cmdr.ReleasesList(app, results)
+	now := time.Now()
+
+	since, err := timeFromString(safeGetValue(args, "--since"), now)
+	if err != nil {
+		return err
+	}
+
+	until, err := timeFromString(safeGetValue(args, "--until"), now)
+	if err != nil {
+		return err
+	}
+
+	sinceVersion := 0
+	if value := safeGetValue(args, "--since-version"); value != "" {
+		if sinceVersion, err = versionFromString(value); err != nil {
+			return err
+		}
+	}
+
+	return cmdr.ReleasesList(app, results, safeGetValue(args, "--owner"), safeGetValue(args, "--summary"),
+		since, until, sinceVersion)
 }
 
 func releasesInfo(argv []string, cmdr cmd.Commander) error {
@@ -103,7 +141,9 @@ Options:
 
 func releasesRollback(argv []string, cmdr cmd.Commander) error {
 	usage := `
-Rolls back to a previous application release.
+Rolls back to a previous application release, or to the release before the
+current one if no version is given. It first shows the release it will restore and
+what differs from the current release, and asks for confirmation.
 
 Usage: deis releases:rollback [<version>] [options]
 
@@ -114,6 +154,15 @@ Arguments:
 Options:
   -a --app=<app>
     the uniquely identifiable name of the application.
+  --confirm=<app>
+    skips the prompt for the application name. <app> is the uniquely identifiable
+    name for the application.
+  -y --yes
+    skips the confirmation.
+  --wait
+    wait for the processes to be up on the new release, failing if any is crashing.
+  --timeout=<duration>
+    how long --wait waits, such as 90s or 10m. [default: 5m]
 `
 
 	args, err := docopt.Parse(usage, argv, true, "", false, true)
@@ -121,6 +170,11 @@ Options:
 		return err
 	}
 
+	wait, err := waitTimeout(args)
+	if err != nil {
+		return err
+	}
+
 	var version int
 
 	if args["<version>"] == nil {
@@ -135,7 +189,47 @@ Options:
 
 	app := safeGetValue(args, "--app")
 
-	return cmdr.ReleasesRollback(app, version)
+	return cmdr.ReleasesRollback(app, version, wait, safeGetValue(args, "--confirm"), args["--yes"].(bool))
+}
+
+func releasesDiff(argv []string, cmdr cmd.Commander) error {
+	usage := `
+Shows what changed between two releases: a unified diff of their builds' images,
+process types and sidecars, and the releases between them that changed the config.
+The controller only keeps the current config, so its values, such as the environment,
+limits and healthchecks, are shown when one of the releases uses it, with secrets
+masked. Diffing the current release against an older one shows what rolling back to
+it would undo.
+
+Usage: deis releases:diff <from> <to> [options]
+
+Arguments:
+  <from>
+    the release to compare from, such as 'v12'.
+  <to>
+    the release to compare to, such as 'v15'.
+
+Options:
+  -a --app=<app>
+    the uniquely identifiable name for the application.
+`
+
+	args, err := docopt.Parse(usage, argv, true, "", false, true)
+	if err != nil {
+		return err
+	}
+
+	from, err := versionFromString(args["<from>"].(string))
+	if err != nil {
+		return err
+	}
+
+	to, err := versionFromString(args["<to>"].(string))
+	if err != nil {
+		return err
+	}
+
+	return cmdr.ReleasesDiff(safeGetValue(args, "--app"), from, to)
 }
 
 func versionFromString(version string) (int, error) {
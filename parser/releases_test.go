@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/arschles/assert"
 	"github.com/deis/workflow-cli/pkg/testutil"
@@ -12,7 +13,7 @@ import (
 // Create fake implementations of each method that return the argument
 // we expect to have called the function (as an error to satisfy the interface).
 
-func (d FakeDeisCmd) ReleasesList(string, int) error {
+func (d FakeDeisCmd) ReleasesList(string, int, string, string, time.Time, time.Time, int) error {
 	return errors.New("releases:list")
 }
 
@@ -20,10 +21,14 @@ func (d FakeDeisCmd) ReleasesInfo(string, int) error {
 	return errors.New("releases:info")
 }
 
-func (d FakeDeisCmd) ReleasesRollback(string, int) error {
+func (d FakeDeisCmd) ReleasesRollback(string, int, time.Duration, string, bool) error {
 	return errors.New("releases:rollback")
 }
 
+func (d FakeDeisCmd) ReleasesDiff(string, int, int) error {
+	return errors.New("releases:diff")
+}
+
 func TestReleases(t *testing.T) {
 	t.Parallel()
 
@@ -45,6 +50,14 @@ func TestReleases(t *testing.T) {
 			args:     []string{"releases:list"},
 			expected: "",
 		},
+		{
+			args:     []string{"releases:list", "--owner=admin", "--summary=config", "--since=168h", "--since-version=v12"},
+			expected: "",
+		},
+		{
+			args:     []string{"releases:list", "--since=last week"},
+			expected: "last week is not a time, such as 2017-03-10T16:00:00Z, or a duration, such as 30m",
+		},
 		{
 			args:     []string{"releases:info", "v1"},
 			expected: "",
@@ -57,6 +70,22 @@ func TestReleases(t *testing.T) {
 			args:     []string{"releases:rollback", "v1"},
 			expected: "",
 		},
+		{
+			args:     []string{"releases:rollback", "v1", "--wait"},
+			expected: "",
+		},
+		{
+			args:     []string{"releases:rollback", "--yes"},
+			expected: "",
+		},
+		{
+			args:     []string{"releases:rollback", "v3", "--confirm=foo"},
+			expected: "",
+		},
+		{
+			args:     []string{"releases:diff", "v12", "15"},
+			expected: "",
+		},
 		{
 			args:     []string{"releases"},
 			expected: "releases:list",
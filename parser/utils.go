@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"strconv"
+	"time"
 
 	"github.com/deis/workflow-cli/cmd"
 )
@@ -34,6 +35,35 @@ func responseLimit(limit string) (int, error) {
 	return strconv.Atoi(limit)
 }
 
+// waitTimeout returns how long to wait for processes, or zero if --wait wasn't given.
+func waitTimeout(args map[string]interface{}) (time.Duration, error) {
+	if wait, _ := args["--wait"].(bool); !wait {
+		return 0, nil
+	}
+
+	return time.ParseDuration(safeGetValue(args, "--timeout"))
+}
+
+// timeFromString parses a time, such as 2017-03-10T16:00:00Z, or a duration before now, such
+// as 30m. An empty string is the zero time.
+func timeFromString(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if ago, err := time.ParseDuration(value); err == nil {
+		return now.Add(-ago), nil
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%s is not a time, such as 2017-03-10T16:00:00Z, or a duration, such as 30m", value)
+}
+
 // PrintUsage runs if no matching command is found.
 func PrintUsage(cmdr cmd.Commander) {
 	cmdr.PrintErrln("Found no matching command, try 'deis help'")
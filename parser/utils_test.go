@@ -1,6 +1,9 @@
 package parser
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestSafeGet(t *testing.T) {
 	t.Parallel()
@@ -72,3 +75,30 @@ func TestPrintHelp(t *testing.T) {
 		t.Error("Expected false")
 	}
 }
+
+func TestTimeFromString(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2017, 3, 10, 16, 0, 0, 0, time.UTC)
+
+	cases := map[string]time.Time{
+		"":                     {},
+		"30m":                  time.Date(2017, 3, 10, 15, 30, 0, 0, time.UTC),
+		"2017-03-09T12:00:00Z": time.Date(2017, 3, 9, 12, 0, 0, 0, time.UTC),
+		"2017-03-09":           time.Date(2017, 3, 9, 0, 0, 0, 0, time.UTC),
+	}
+
+	for value, expected := range cases {
+		actual, err := timeFromString(value, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !actual.Equal(expected) {
+			t.Errorf("Expected %v, Got %v", expected, actual)
+		}
+	}
+
+	if _, err := timeFromString("yesterday", now); err == nil {
+		t.Error("Expected an error")
+	}
+}
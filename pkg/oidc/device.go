@@ -0,0 +1,201 @@
+package oidc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DeviceCodeGrantType is the grant type of the device authorization flow.
+const DeviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+// defaultInterval is how long to wait between polls when the provider doesn't say.
+const defaultInterval = 5 * time.Second
+
+// ErrAccessDenied is returned when the user denies the login.
+var ErrAccessDenied = errors.New("the login was denied")
+
+// ErrExpired is returned when the user doesn't approve the login before the code expires.
+var ErrExpired = errors.New("the login code expired before it was approved")
+
+// sleep waits between polls, and is replaced in tests.
+var sleep = time.Sleep
+
+// Provider is an OpenID Connect provider that supports the device authorization flow.
+type Provider struct {
+	Issuer                      string `json:"issuer"`
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+	TokenEndpoint               string `json:"token_endpoint"`
+	client                      *http.Client
+}
+
+// DeviceAuthorization is the code the user enters to approve a login, and where.
+type DeviceAuthorization struct {
+	DeviceCode string `json:"device_code"`
+	UserCode   string `json:"user_code"`
+	// VerificationURI is where the user enters the code.
+	VerificationURI string `json:"verification_uri"`
+	// VerificationURIComplete, if the provider supports it, has the code filled in already.
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	// ExpiresIn and Interval are in seconds.
+	ExpiresIn int `json:"expires_in"`
+	Interval  int `json:"interval"`
+}
+
+// Token is what the provider returns once the user approves the login.
+type Token struct {
+	AccessToken string `json:"access_token"`
+	IDToken     string `json:"id_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// tokenError is the body of a failed token request (RFC 6749, section 5.2).
+type tokenError struct {
+	Error       string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+// Discover fetches the configuration of the provider at an issuer URL.
+func Discover(client *http.Client, issuer string) (*Provider, error) {
+	u := strings.TrimRight(issuer, "/") + "/.well-known/openid-configuration"
+
+	res, err := client.Get(u)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not discover the provider at %s: %s", issuer, readError(res.Body, res.Status))
+	}
+
+	p := Provider{client: client}
+	if err = json.NewDecoder(res.Body).Decode(&p); err != nil {
+		return nil, fmt.Errorf("invalid configuration from %s: %v", u, err)
+	}
+
+	if p.DeviceAuthorizationEndpoint == "" {
+		return nil, fmt.Errorf("the provider at %s doesn't support the device authorization flow", issuer)
+	}
+
+	return &p, nil
+}
+
+// AuthorizeDevice starts a login, returning the code for the user to approve it with.
+func (p *Provider) AuthorizeDevice(clientID string, scopes []string) (*DeviceAuthorization, error) {
+	res, err := p.client.PostForm(p.DeviceAuthorizationEndpoint, url.Values{
+		"client_id": {clientID},
+		"scope":     {strings.Join(scopes, " ")},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not start the login: %s", readError(res.Body, res.Status))
+	}
+
+	var auth DeviceAuthorization
+	if err = json.NewDecoder(res.Body).Decode(&auth); err != nil {
+		return nil, fmt.Errorf("invalid device authorization: %v", err)
+	}
+
+	return &auth, nil
+}
+
+// PollToken polls for the token until the user approves the login, denies it or the code
+// expires, waiting as long between polls as the provider asks.
+func (p *Provider) PollToken(clientID string, auth *DeviceAuthorization) (*Token, error) {
+	interval := time.Duration(auth.Interval) * time.Second
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+
+	var deadline time.Time
+	if auth.ExpiresIn > 0 {
+		deadline = time.Now().Add(time.Duration(auth.ExpiresIn) * time.Second)
+	}
+
+	for {
+		token, failure := p.requestToken(clientID, auth.DeviceCode)
+		if failure == nil {
+			return token, nil
+		}
+
+		switch failure.Error {
+		case "authorization_pending":
+		case "slow_down":
+			interval += 5 * time.Second
+		case "access_denied":
+			return nil, ErrAccessDenied
+		case "expired_token":
+			return nil, ErrExpired
+		default:
+			if failure.Description != "" {
+				return nil, fmt.Errorf("could not log in: %s: %s", failure.Error, failure.Description)
+			}
+			return nil, fmt.Errorf("could not log in: %s", failure.Error)
+		}
+
+		if !deadline.IsZero() && time.Now().Add(interval).After(deadline) {
+			return nil, ErrExpired
+		}
+
+		sleep(interval)
+	}
+}
+
+func (p *Provider) requestToken(clientID, deviceCode string) (*Token, *tokenError) {
+	res, err := p.client.PostForm(p.TokenEndpoint, url.Values{
+		"grant_type":  {DeviceCodeGrantType},
+		"device_code": {deviceCode},
+		"client_id":   {clientID},
+	})
+	if err != nil {
+		return nil, &tokenError{Error: "request_failed", Description: err.Error()}
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, &tokenError{Error: "request_failed", Description: err.Error()}
+	}
+
+	if res.StatusCode != http.StatusOK {
+		tokenErr := tokenError{}
+		if json.Unmarshal(body, &tokenErr) != nil || tokenErr.Error == "" {
+			return nil, &tokenError{Error: res.Status, Description: strings.TrimSpace(string(body))}
+		}
+		return nil, &tokenErr
+	}
+
+	var token Token
+	if err = json.Unmarshal(body, &token); err != nil {
+		return nil, &tokenError{Error: "invalid_token_response", Description: err.Error()}
+	}
+
+	return &token, nil
+}
+
+// readError returns the error a provider responded with, or status if it didn't say.
+func readError(body io.Reader, status string) string {
+	contents, _ := ioutil.ReadAll(io.LimitReader(body, 64*1024))
+
+	tokenErr := tokenError{}
+	if json.Unmarshal(contents, &tokenErr) == nil && tokenErr.Error != "" {
+		if tokenErr.Description != "" {
+			return tokenErr.Error + ": " + tokenErr.Description
+		}
+		return tokenErr.Error
+	}
+
+	return status
+}
@@ -0,0 +1,92 @@
+package oidc
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/deis/workflow-cli/pkg/testutil"
+)
+
+func TestDeviceLogin(t *testing.T) {
+	var waited []time.Duration
+	sleep = func(d time.Duration) { waited = append(waited, d) }
+	defer func() { sleep = time.Sleep }()
+
+	idp := testutil.NewTestProvider("deis")
+	defer idp.Close()
+	idp.Pending = 2
+
+	p, err := Discover(http.DefaultClient, idp.Server.URL+"/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	auth, err := p.AuthorizeDevice("deis", []string{"openid", "profile"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if auth.UserCode != "WDJB-MJHT" || auth.VerificationURI != idp.Server.URL+"/device" {
+		t.Fatalf("unexpected device authorization %+v", auth)
+	}
+
+	token, err := p.PollToken("deis", auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.IDToken != "id-token" {
+		t.Errorf("expected the ID token, got %q", token.IDToken)
+	}
+	if idp.Polls() != 3 {
+		t.Errorf("expected 3 polls, got %d", idp.Polls())
+	}
+	if len(waited) != 2 || waited[0] != time.Second {
+		t.Errorf("expected to wait a second twice, waited %v", waited)
+	}
+}
+
+func TestDeviceLoginDenied(t *testing.T) {
+	idp := testutil.NewTestProvider("deis")
+	defer idp.Close()
+	idp.Deny = true
+
+	p, err := Discover(http.DefaultClient, idp.Server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = p.AuthorizeDevice("other", nil); err == nil || err.Error() != "could not start the login: invalid_client" {
+		t.Errorf("expected the client to be refused, got %v", err)
+	}
+
+	auth, err := p.AuthorizeDevice("deis", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = p.PollToken("deis", auth); err != ErrAccessDenied {
+		t.Errorf("expected the login to be denied, got %v", err)
+	}
+
+	// A code that has expired isn't polled for again.
+	auth.ExpiresIn = 1
+	idp.Deny = false
+	idp.Pending = 100
+	if _, err = p.PollToken("deis", auth); err != ErrExpired {
+		t.Errorf("expected the code to expire, got %v", err)
+	}
+}
+
+func TestDiscoverUnsupported(t *testing.T) {
+	server := testutil.NewTestServer()
+	defer server.Close()
+
+	server.Mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"issuer":"` + server.Server.URL + `","token_endpoint":"/token"}`))
+	})
+
+	_, err := Discover(http.DefaultClient, server.Server.URL)
+	if err == nil || err.Error() != "the provider at "+server.Server.URL+" doesn't support the device authorization flow" {
+		t.Errorf("expected the provider to be unsupported, got %v", err)
+	}
+}
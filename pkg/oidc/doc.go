@@ -0,0 +1,3 @@
+// Package oidc logs in to an OpenID Connect provider with the device authorization grant
+// (RFC 8628), where the user approves the login in a browser, on this or another machine.
+package oidc
@@ -0,0 +1,105 @@
+package testutil
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+)
+
+// TestProvider is a stand-in OpenID Connect provider for logins with the device authorization
+// flow. It answers that a login is pending for the first Pending polls, then issues IDToken,
+// or denies the login if Deny is set.
+type TestProvider struct {
+	Server   *httptest.Server
+	ClientID string
+	UserCode string
+	IDToken  string
+	Pending  int
+	Deny     bool
+
+	mu    sync.Mutex
+	polls int
+}
+
+// testDeviceCode is the device code the stand-in provider hands out.
+const testDeviceCode = "device-code"
+
+// NewTestProvider starts a stand-in provider that accepts logins from a client ID.
+func NewTestProvider(clientID string) *TestProvider {
+	p := &TestProvider{ClientID: clientID, UserCode: "WDJB-MJHT", IDToken: "id-token"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.serveConfiguration)
+	mux.HandleFunc("/device/code", p.serveDeviceCode)
+	mux.HandleFunc("/token", p.serveToken)
+	p.Server = httptest.NewServer(mux)
+
+	return p
+}
+
+// Close shuts the provider down.
+func (p *TestProvider) Close() {
+	p.Server.Close()
+}
+
+// Polls returns how many times the token was polled for.
+func (p *TestProvider) Polls() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.polls
+}
+
+func (p *TestProvider) serveConfiguration(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                        p.Server.URL,
+		"device_authorization_endpoint": p.Server.URL + "/device/code",
+		"token_endpoint":                p.Server.URL + "/token",
+	})
+}
+
+func (p *TestProvider) serveDeviceCode(w http.ResponseWriter, r *http.Request) {
+	if r.PostFormValue("client_id") != p.ClientID {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"device_code":               testDeviceCode,
+		"user_code":                 p.UserCode,
+		"verification_uri":          p.Server.URL + "/device",
+		"verification_uri_complete": p.Server.URL + "/device?user_code=" + p.UserCode,
+		"expires_in":                600,
+		"interval":                  1,
+	})
+}
+
+func (p *TestProvider) serveToken(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.polls++
+
+	switch {
+	case r.PostFormValue("grant_type") != "urn:ietf:params:oauth:grant-type:device_code":
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+	case r.PostFormValue("client_id") != p.ClientID || r.PostFormValue("device_code") != testDeviceCode:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+	case p.polls <= p.Pending:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "authorization_pending"})
+	case p.Deny:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "access_denied"})
+	default:
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"access_token": "access-token",
+			"id_token":     p.IDToken,
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		})
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -84,17 +84,22 @@ func AssertBody(t *testing.T, expected interface{}, req *http.Request) {
 	}
 }
 
-// StripProgress strips the output from the progress method
+// StripProgress strips the output from the progress method, which may have run several times
 func StripProgress(input string) string {
-	first := strings.Index(input, "\b")
-	// If \b charecter not part of string
-	if first == -1 {
-		return input
+	for {
+		first := strings.Index(input, "\b")
+		// If \b charecter not part of string
+		if first == -1 {
+			return input
+		}
+		last := first
+		for last+1 < len(input) && input[last+1] == '\b' {
+			last++
+		}
+
+		// strip the \b characters and the characters they delete.
+		input = input[:first-(last-first+1)] + input[last+1:]
 	}
-	last := strings.LastIndex(input, "\b")
-
-	// return string without \b or the characters it deletes.
-	return input[:first-(last-first+1)] + input[last+1:]
 }
 
 // SetHeaders sets standard headers for requests
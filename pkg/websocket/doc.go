@@ -0,0 +1,4 @@
+// Package websocket is a minimal implementation of the WebSocket protocol (RFC 6455), enough
+// to stream the input and output of commands run in app containers. It dials connections for
+// the CLI and upgrades them for stand-in servers in tests.
+package websocket
@@ -0,0 +1,457 @@
+package websocket
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Message types, as the opcodes of the frames that carry them.
+const (
+	TextMessage   = 1
+	BinaryMessage = 2
+	CloseMessage  = 8
+	PingMessage   = 9
+	PongMessage   = 10
+)
+
+// maxMessageSize is the largest message read, to not exhaust memory on a corrupt frame.
+const maxMessageSize = 32 << 20
+
+// acceptGUID is appended to the key of a handshake to compute the accept header.
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// ErrMessageTooLarge is returned when a message is bigger than 32MB.
+var ErrMessageTooLarge = errors.New("websocket: message too large")
+
+// ErrBadHandshake is returned when a server doesn't upgrade the connection. Dial returns the
+// server's response along with it, to tell why.
+var ErrBadHandshake = errors.New("websocket: bad handshake")
+
+// ErrClosed is returned when writing to a connection after a close message was sent.
+var ErrClosed = errors.New("websocket: connection closed")
+
+// ErrCloseReceived is returned when reading from a connection the other end closed with a
+// close message, which is how a conversation ends cleanly.
+var ErrCloseReceived = errors.New("websocket: close message received")
+
+// ErrConnectionLost is returned when reading from a connection that ended without a close
+// message, such as when a proxy timed out or the network went down.
+var ErrConnectionLost = errors.New("websocket: connection lost")
+
+// Conn is a WebSocket connection. Messages can be read and written concurrently, but only
+// one goroutine may read at a time.
+type Conn struct {
+	conn   net.Conn
+	r      *bufio.Reader
+	client bool
+	wmu    sync.Mutex
+	closed bool
+}
+
+// proxyFromEnvironment picks the proxy to dial through, as other requests to the controller
+// do. Tests replace it, since the environment is only read once and never proxies localhost.
+var proxyFromEnvironment = http.ProxyFromEnvironment
+
+// Dial opens a WebSocket connection to an http or https URL, offering the given
+// subprotocols. It goes through the proxy set by $HTTP_PROXY, $HTTPS_PROXY and $NO_PROXY, if
+// any. It returns the server's handshake response, whose Sec-WebSocket-Protocol header is the
+// subprotocol it chose. If the server refuses, the error is ErrBadHandshake and the response
+// holds its status and body.
+func Dial(u *url.URL, header http.Header, tlsConfig *tls.Config,
+	protocols ...string) (*Conn, *http.Response, error) {
+	secure := false
+	switch u.Scheme {
+	case "http", "ws":
+	case "https", "wss":
+		secure = true
+	default:
+		return nil, nil, fmt.Errorf("websocket: unsupported scheme %s", u.Scheme)
+	}
+
+	host := u.Host
+	if u.Port() == "" {
+		if secure {
+			host = net.JoinHostPort(u.Hostname(), "443")
+		} else {
+			host = net.JoinHostPort(u.Hostname(), "80")
+		}
+	}
+
+	conn, err := dialThroughProxy(u, host, secure)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if secure {
+		config := &tls.Config{}
+		if tlsConfig != nil {
+			config = tlsConfig.Clone()
+		}
+		if config.ServerName == "" {
+			config.ServerName = u.Hostname()
+		}
+
+		tlsConn := tls.Client(conn, config)
+		if err = tlsConn.Handshake(); err != nil {
+			conn.Close()
+			return nil, nil, err
+		}
+		conn = tlsConn
+	}
+
+	r := bufio.NewReader(conn)
+
+	res, err := handshake(conn, r, u, header, protocols)
+	if err != nil {
+		conn.Close()
+		return nil, res, err
+	}
+
+	return &Conn{conn: conn, r: r, client: true}, res, nil
+}
+
+// dialThroughProxy connects to host, tunneling through the proxy for u with a CONNECT request
+// if there is one.
+func dialThroughProxy(u *url.URL, host string, secure bool) (net.Conn, error) {
+	// The proxy is chosen by the http or https URL, as the environment only names those.
+	target := *u
+	if secure {
+		target.Scheme = "https"
+	} else {
+		target.Scheme = "http"
+	}
+
+	proxy, err := proxyFromEnvironment(&http.Request{URL: &target})
+	if err != nil {
+		return nil, err
+	} else if proxy == nil {
+		return net.Dial("tcp", host)
+	}
+
+	proxyHost := proxy.Host
+	if proxy.Port() == "" {
+		if proxy.Scheme == "https" {
+			proxyHost = net.JoinHostPort(proxy.Hostname(), "443")
+		} else {
+			proxyHost = net.JoinHostPort(proxy.Hostname(), "80")
+		}
+	}
+
+	var conn net.Conn
+	if proxy.Scheme == "https" {
+		conn, err = tls.Dial("tcp", proxyHost, &tls.Config{ServerName: proxy.Hostname()})
+	} else {
+		conn, err = net.Dial("tcp", proxyHost)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	req := &http.Request{
+		Method: "CONNECT",
+		URL:    &url.URL{Opaque: host},
+		Host:   host,
+		Header: http.Header{},
+	}
+	if proxy.User != nil {
+		password, _ := proxy.User.Password()
+		credentials := proxy.User.Username() + ":" + password
+		req.Header.Set("Proxy-Authorization",
+			"Basic "+base64.StdEncoding.EncodeToString([]byte(credentials)))
+	}
+
+	if err = req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	// The proxy only answers the CONNECT request, so nothing the server sends is buffered here.
+	res, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("websocket: proxy %s refused to connect to %s: %s", proxy.Host, host,
+			res.Status)
+	}
+
+	return conn, nil
+}
+
+func handshake(conn net.Conn, r *bufio.Reader, u *url.URL, header http.Header,
+	protocols []string) (*http.Response, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	target := *u
+	switch target.Scheme {
+	case "ws":
+		target.Scheme = "http"
+	case "wss":
+		target.Scheme = "https"
+	}
+
+	req, err := http.NewRequest("GET", target.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	if len(protocols) > 0 {
+		req.Header.Set("Sec-WebSocket-Protocol", strings.Join(protocols, ", "))
+	}
+
+	if err = req.Write(conn); err != nil {
+		return nil, err
+	}
+
+	res, err := http.ReadResponse(r, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusSwitchingProtocols {
+		// Keep the body readable once the connection is closed.
+		body, _ := ioutil.ReadAll(io.LimitReader(res.Body, 64*1024))
+		res.Body.Close()
+		res.Body = ioutil.NopCloser(bytes.NewReader(body))
+		return res, ErrBadHandshake
+	}
+
+	if res.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		return res, ErrBadHandshake
+	}
+
+	return res, nil
+}
+
+// Upgrade upgrades an HTTP request to a WebSocket connection, choosing protocol as the
+// subprotocol if it isn't empty. It's meant for stand-in servers in tests.
+func Upgrade(w http.ResponseWriter, r *http.Request, protocol string) (*Conn, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") || key == "" {
+		http.Error(w, "not a websocket handshake", http.StatusBadRequest)
+		return nil, ErrBadHandshake
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, errors.New("websocket: response doesn't support hijacking")
+	}
+
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	response := "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n"
+	if protocol != "" {
+		response += "Sec-WebSocket-Protocol: " + protocol + "\r\n"
+	}
+
+	if _, err = conn.Write([]byte(response + "\r\n")); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &Conn{conn: conn, r: rw.Reader}, nil
+}
+
+func acceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// ReadMessage reads the next text or binary message, answering pings on the way. It returns
+// ErrCloseReceived once the other end closes the connection, and ErrConnectionLost if the
+// connection ends without a close message.
+func (c *Conn) ReadMessage() (int, []byte, error) {
+	var messageType int
+	var message []byte
+
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err == ErrCloseReceived {
+			c.writeFrame(CloseMessage, payload)
+			return 0, nil, err
+		} else if err != nil {
+			return 0, nil, err
+		}
+
+		switch opcode {
+		case PingMessage:
+			if err = c.writeFrame(PongMessage, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case PongMessage:
+			continue
+		case 0:
+			// A continuation of a fragmented message.
+		default:
+			messageType = opcode
+			message = nil
+		}
+
+		if len(message)+len(payload) > maxMessageSize {
+			return 0, nil, ErrMessageTooLarge
+		}
+		message = append(message, payload...)
+
+		if fin {
+			return messageType, message, nil
+		}
+	}
+}
+
+func (c *Conn) readFrame() (bool, int, []byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(c.r, header[:]); err == io.EOF {
+		return false, 0, nil, ErrConnectionLost
+	} else if err != nil {
+		return false, 0, nil, err
+	}
+
+	fin := header[0]&0x80 != 0
+	opcode := int(header[0] & 0x0f)
+	masked := header[1]&0x80 != 0
+	length := uint64(header[1] & 0x7f)
+
+	switch length {
+	case 126:
+		var extended [2]byte
+		if _, err := io.ReadFull(c.r, extended[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		if _, err := io.ReadFull(c.r, extended[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(extended[:])
+	}
+
+	if length > maxMessageSize {
+		return false, 0, nil, ErrMessageTooLarge
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.r, mask[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		return false, 0, nil, err
+	}
+
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+
+	if opcode == CloseMessage {
+		return fin, opcode, payload, ErrCloseReceived
+	}
+
+	return fin, opcode, payload, nil
+}
+
+// WriteMessage writes a message of a type, such as BinaryMessage, in a single frame.
+func (c *Conn) WriteMessage(messageType int, data []byte) error {
+	return c.writeFrame(messageType, data)
+}
+
+func (c *Conn) writeFrame(opcode int, payload []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	if c.closed {
+		return ErrClosed
+	}
+
+	frame := []byte{0x80 | byte(opcode)}
+
+	var maskBit byte
+	if c.client {
+		// Clients must mask every frame they send.
+		maskBit = 0x80
+	}
+
+	switch length := len(payload); {
+	case length < 126:
+		frame = append(frame, maskBit|byte(length))
+	case length <= 0xffff:
+		frame = append(frame, maskBit|126, 0, 0)
+		binary.BigEndian.PutUint16(frame[2:], uint16(length))
+	default:
+		frame = append(frame, maskBit|127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(frame[2:], uint64(length))
+	}
+
+	if c.client {
+		var mask [4]byte
+		if _, err := rand.Read(mask[:]); err != nil {
+			return err
+		}
+		frame = append(frame, mask[:]...)
+
+		masked := make([]byte, len(payload))
+		for i := range payload {
+			masked[i] = payload[i] ^ mask[i%4]
+		}
+		payload = masked
+	}
+
+	_, err := c.conn.Write(append(frame, payload...))
+
+	if opcode == CloseMessage {
+		c.closed = true
+	}
+
+	return err
+}
+
+// Close sends a close message, if one wasn't sent yet, and closes the connection.
+func (c *Conn) Close() error {
+	c.writeFrame(CloseMessage, []byte{0x03, 0xe8})
+	return c.conn.Close()
+}
+
+// Abort closes the connection without a close message, as a dropped connection would end.
+// It's meant for stand-in servers in tests.
+func (c *Conn) Abort() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,233 @@
+package websocket
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestDial(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token abc" {
+			t.Errorf("expected the authorization header, got %q", r.Header.Get("Authorization"))
+		}
+
+		conn, err := Upgrade(w, r, "v1.echo")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+
+		for {
+			messageType, message, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			if err = conn.WriteMessage(messageType, message); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL + "/echo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn, res, err := Dial(u, http.Header{"Authorization": {"token abc"}}, nil, "v2.echo", "v1.echo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if protocol := res.Header.Get("Sec-WebSocket-Protocol"); protocol != "v1.echo" {
+		t.Fatalf("expected protocol v1.echo, got %s", protocol)
+	}
+
+	// Sizes that use each of the three length encodings.
+	for _, size := range []int{5, 300, 70000} {
+		message := bytes.Repeat([]byte{'x'}, size)
+		if err = conn.WriteMessage(BinaryMessage, message); err != nil {
+			t.Fatal(err)
+		}
+
+		messageType, echoed, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if messageType != BinaryMessage || !bytes.Equal(echoed, message) {
+			t.Fatalf("expected a binary echo of %d bytes, got type %d and %d bytes", size, messageType,
+				len(echoed))
+		}
+	}
+
+	if err = conn.WriteMessage(CloseMessage, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = conn.ReadMessage(); err != ErrCloseReceived {
+		t.Fatalf("expected a close message, got %v", err)
+	}
+}
+
+func TestConnectionLost(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r, "")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		conn.WriteMessage(BinaryMessage, []byte("partial"))
+		// Drop the connection without a close message, as a proxy timing out would.
+		conn.Abort()
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn, _, err := Dial(u, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if _, message, err := conn.ReadMessage(); err != nil || string(message) != "partial" {
+		t.Fatalf("expected the message sent before the connection dropped, got %q and %v", message, err)
+	}
+	if _, _, err = conn.ReadMessage(); err != ErrConnectionLost {
+		t.Fatalf("expected the connection to be lost, got %v", err)
+	}
+}
+
+func TestDialRefused(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, res, err := Dial(u, nil, nil)
+	if err != ErrBadHandshake {
+		t.Fatalf("expected a bad handshake, got %v", err)
+	}
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected status 401, got %d", res.StatusCode)
+	}
+}
+
+func TestDialThroughProxy(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r, "")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+
+		conn.WriteMessage(TextMessage, []byte("hello"))
+	}))
+	defer server.Close()
+
+	tunneled := make(chan string, 1)
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "CONNECT" {
+			t.Errorf("expected a CONNECT request, got %s", r.Method)
+			return
+		}
+		// user:secret, from the proxy URL.
+		if auth := r.Header.Get("Proxy-Authorization"); auth != "Basic dXNlcjpzZWNyZXQ=" {
+			t.Errorf("expected the proxy credentials, got %q", auth)
+		}
+		tunneled <- r.Host
+
+		upstream, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer upstream.Close()
+
+		client, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer client.Close()
+
+		client.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+		go io.Copy(upstream, client)
+		io.Copy(client, upstream)
+	}))
+	defer proxy.Close()
+
+	proxyURL, err := url.Parse(proxy.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxyURL.User = url.UserPassword("user", "secret")
+
+	proxyFromEnvironment = http.ProxyURL(proxyURL)
+	defer func() { proxyFromEnvironment = http.ProxyFromEnvironment }()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn, _, err := Dial(u, nil, &tls.Config{InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if _, message, err := conn.ReadMessage(); err != nil || string(message) != "hello" {
+		t.Fatalf("expected hello through the proxy, got %q and %v", message, err)
+	}
+	if host := <-tunneled; host != u.Host {
+		t.Fatalf("expected a tunnel to %s, got %s", u.Host, host)
+	}
+}
+
+func TestDialProxyRefused(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusProxyAuthRequired)
+	}))
+	defer proxy.Close()
+
+	proxyURL, err := url.Parse(proxy.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proxyFromEnvironment = http.ProxyURL(proxyURL)
+	defer func() { proxyFromEnvironment = http.ProxyFromEnvironment }()
+
+	u, err := url.Parse("http://controller.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err = Dial(u, nil, nil)
+	expected := fmt.Sprintf("websocket: proxy %s refused to connect to controller.example.com:80: "+
+		"407 Proxy Authentication Required", proxyURL.Host)
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected %q, got %v", expected, err)
+	}
+}
@@ -0,0 +1,280 @@
+package settings
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// Names of the credential stores that ship with the CLI. Any other name is a credential
+// helper, a program named deis-credential-<name> on the PATH.
+const (
+	// FileCredentialStore keeps the token in the settings file, which is the default.
+	FileCredentialStore = "file"
+	// EncryptedFileCredentialStore keeps the token in a file next to the settings file,
+	// encrypted with a passphrase.
+	EncryptedFileCredentialStore = "encrypted-file"
+)
+
+// CredentialHelperPrefix is prepended to the name of a credential store to find its helper.
+const CredentialHelperPrefix = "deis-credential-"
+
+// ErrCredentialsNotFound is returned when a credential store has no token for a controller.
+var ErrCredentialsNotFound = errors.New("credentials not found")
+
+// CredentialStore keeps the token of a user on a controller outside of the settings.
+type CredentialStore interface {
+	Get(controller, username string) (string, error)
+	Store(controller, username, token string) error
+	Erase(controller, username string) error
+}
+
+// Passphrase returns the passphrase of the encrypted-file credential store. It reads
+// $DEIS_CREDENTIAL_PASSPHRASE, or prompts for it if stdin is a terminal.
+var Passphrase = func() (string, error) {
+	if v, ok := os.LookupEnv("DEIS_CREDENTIAL_PASSPHRASE"); ok {
+		return v, nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !terminal.IsTerminal(fd) {
+		return "", errors.New("the credential store is encrypted, set DEIS_CREDENTIAL_PASSPHRASE to unlock it")
+	}
+
+	fmt.Fprint(os.Stderr, "credential store passphrase: ")
+	passphrase, err := terminal.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+
+	return string(passphrase), err
+}
+
+// credentialStore returns the store named by a settings file. filename is the path of the
+// settings file, which the file backends keep the token in or next to.
+func credentialStore(sF *settingsFile, filename string) CredentialStore {
+	switch sF.CredentialStore {
+	case "", FileCredentialStore:
+		return &fileStore{settings: sF}
+	case EncryptedFileCredentialStore:
+		return &encryptedFileStore{filename: credentialsFile(filename)}
+	default:
+		return &helperStore{program: CredentialHelperPrefix + sF.CredentialStore}
+	}
+}
+
+// credentialsFile is where the encrypted-file store keeps the token of a settings file.
+func credentialsFile(filename string) string {
+	return strings.TrimSuffix(filename, ".json") + ".credentials"
+}
+
+// fileStore keeps the token in the settings file, as the CLI always has.
+type fileStore struct {
+	settings *settingsFile
+}
+
+func (f *fileStore) Get(controller, username string) (string, error) {
+	if f.settings.Token == "" {
+		return "", ErrCredentialsNotFound
+	}
+
+	return f.settings.Token, nil
+}
+
+func (f *fileStore) Store(controller, username, token string) error {
+	f.settings.Token = token
+	return nil
+}
+
+func (f *fileStore) Erase(controller, username string) error {
+	f.settings.Token = ""
+	return nil
+}
+
+// encryptedCredentials is the file of the encrypted-file store. The token is encrypted with
+// AES-GCM, using a key derived from the passphrase with scrypt. The controller and username
+// are authenticated along with it, so the file can't be swapped into another profile.
+type encryptedCredentials struct {
+	Salt  []byte `json:"salt"`
+	Nonce []byte `json:"nonce"`
+	Token []byte `json:"token"`
+}
+
+type encryptedFileStore struct {
+	filename string
+}
+
+func (e *encryptedFileStore) Get(controller, username string) (string, error) {
+	contents, err := ioutil.ReadFile(e.filename)
+	if os.IsNotExist(err) {
+		return "", ErrCredentialsNotFound
+	} else if err != nil {
+		return "", err
+	}
+
+	var credentials encryptedCredentials
+	if err = json.Unmarshal(contents, &credentials); err != nil {
+		return "", fmt.Errorf("%s is corrupt: %v", e.filename, err)
+	}
+
+	passphrase, err := Passphrase()
+	if err != nil {
+		return "", err
+	}
+
+	aead, err := newCredentialCipher(passphrase, credentials.Salt)
+	if err != nil {
+		return "", err
+	}
+
+	token, err := aead.Open(nil, credentials.Nonce, credentials.Token,
+		[]byte(controller+"\x00"+username))
+	if err != nil {
+		return "", fmt.Errorf("could not decrypt %s, is the passphrase right?", e.filename)
+	}
+
+	return string(token), nil
+}
+
+func (e *encryptedFileStore) Store(controller, username, token string) error {
+	passphrase, err := Passphrase()
+	if err != nil {
+		return err
+	}
+
+	credentials := encryptedCredentials{Salt: make([]byte, 16)}
+	if _, err = rand.Read(credentials.Salt); err != nil {
+		return err
+	}
+
+	aead, err := newCredentialCipher(passphrase, credentials.Salt)
+	if err != nil {
+		return err
+	}
+
+	credentials.Nonce = make([]byte, aead.NonceSize())
+	if _, err = rand.Read(credentials.Nonce); err != nil {
+		return err
+	}
+	credentials.Token = aead.Seal(nil, credentials.Nonce, []byte(token),
+		[]byte(controller+"\x00"+username))
+
+	contents, err := json.Marshal(credentials)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(e.filename, contents, 0600)
+}
+
+func (e *encryptedFileStore) Erase(controller, username string) error {
+	if err := os.Remove(e.filename); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+func newCredentialCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// helperCredentials is what a credential helper reads and writes. The protocol is the one of
+// docker's credential helpers, so they can be linked in as deis-credential-<name>:
+//
+//	get    reads a controller URL on stdin and writes its credentials as JSON to stdout
+//	store  reads credentials as JSON on stdin
+//	erase  reads a controller URL on stdin
+//
+// A helper exits with a non-zero status on failure, writing why to stdout. If it has no
+// credentials for the controller, the message is "credentials not found".
+type helperCredentials struct {
+	ServerURL string
+	Username  string
+	Secret    string
+}
+
+type helperStore struct {
+	program string
+}
+
+func (h *helperStore) Get(controller, username string) (string, error) {
+	output, err := h.run("get", strings.NewReader(controller))
+	if err != nil {
+		return "", err
+	}
+
+	var credentials helperCredentials
+	if err = json.Unmarshal(output, &credentials); err != nil {
+		return "", fmt.Errorf("%s get: invalid credentials: %v", h.program, err)
+	}
+
+	if credentials.Secret == "" {
+		return "", ErrCredentialsNotFound
+	}
+
+	return credentials.Secret, nil
+}
+
+func (h *helperStore) Store(controller, username, token string) error {
+	input, err := json.Marshal(helperCredentials{ServerURL: controller, Username: username,
+		Secret: token})
+	if err != nil {
+		return err
+	}
+
+	_, err = h.run("store", bytes.NewReader(input))
+	return err
+}
+
+func (h *helperStore) Erase(controller, username string) error {
+	_, err := h.run("erase", strings.NewReader(controller))
+	return err
+}
+
+// run runs the helper with an action, returning what it wrote to stdout.
+func (h *helperStore) run(action string, input io.Reader) ([]byte, error) {
+	var stdout bytes.Buffer
+	cmd := exec.Command(h.program, action)
+	cmd.Stdin = input
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		if execErr, ok := err.(*exec.Error); ok && execErr.Err == exec.ErrNotFound {
+			return nil, fmt.Errorf("credential helper %s not found on the PATH", h.program)
+		}
+
+		message := strings.TrimSpace(stdout.String())
+		if strings.Contains(message, "credentials not found") {
+			return nil, ErrCredentialsNotFound
+		}
+		if message == "" {
+			message = err.Error()
+		}
+
+		return nil, fmt.Errorf("%s %s: %s", h.program, action, message)
+	}
+
+	return stdout.Bytes(), nil
+}
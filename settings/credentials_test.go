@@ -0,0 +1,149 @@
+package settings
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/arschles/assert"
+)
+
+// stubHelper is a credential helper that keeps the credentials it's given in a file next to it.
+const stubHelper = `#!/bin/sh
+store="$(dirname "$0")/store"
+case "$1" in
+get)
+	read url
+	if [ ! -f "$store" ]; then
+		echo "credentials not found in native keychain"
+		exit 1
+	fi
+	cat "$store"
+	;;
+store)
+	cat > "$store"
+	;;
+erase)
+	read url
+	rm -f "$store"
+	;;
+*)
+	echo "unknown action $1"
+	exit 1
+	;;
+esac
+`
+
+func TestCredentialHelper(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stub credential helper is a shell script")
+	}
+
+	dir, err := ioutil.TempDir("", "helper")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	helper := filepath.Join(dir, "deis-credential-stub")
+	if err = ioutil.WriteFile(helper, []byte(stubHelper), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	path := os.Getenv("PATH")
+	defer os.Setenv("PATH", path)
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+path)
+
+	file, err := createTempProfile(`{"username":"t","controller":"http://foo.bar","credential_store":"stub"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The helper doesn't have a token yet.
+	s, err := Load(file)
+	assert.NoErr(t, err)
+	assert.Equal(t, s.Client.Token, "", "token")
+	assert.Equal(t, s.CredentialStore, "stub", "credential store")
+
+	s.Client.Token = "secret"
+	_, err = s.Save(file)
+	assert.NoErr(t, err)
+
+	contents, err := ioutil.ReadFile(file)
+	assert.NoErr(t, err)
+	assert.Equal(t, strings.Contains(string(contents), "secret"), false, "token in the settings file")
+
+	stored, err := ioutil.ReadFile(filepath.Join(dir, "store"))
+	assert.NoErr(t, err)
+	assert.Equal(t, string(stored), `{"ServerURL":"http://foo.bar","Username":"t","Secret":"secret"}`, "stored credentials")
+
+	s, err = Load(file)
+	assert.NoErr(t, err)
+	assert.Equal(t, s.Client.Token, "secret", "token")
+
+	assert.NoErr(t, Delete(file))
+	if _, err = os.Stat(filepath.Join(dir, "store")); !os.IsNotExist(err) {
+		t.Errorf("expected the helper to erase the credentials, got %v", err)
+	}
+
+	// A helper that isn't installed is an error, rather than a silent logout.
+	file, err = createTempProfile(`{"username":"t","controller":"http://foo.bar","credential_store":"missing"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = Load(file)
+	assert.Err(t, errors.New("credential helper deis-credential-missing not found on the PATH"), err)
+}
+
+func TestEncryptedFileStore(t *testing.T) {
+	passphrase := Passphrase
+	defer func() { Passphrase = passphrase }()
+	Passphrase = func() (string, error) { return "correct horse", nil }
+
+	file, err := createTempProfile(`{"username":"t","controller":"http://foo.bar","token":"a"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	credentials := filepath.Join(filepath.Dir(file), "test.credentials")
+
+	s, err := Load(file)
+	assert.NoErr(t, err)
+
+	s.CredentialStore = EncryptedFileCredentialStore
+	_, err = s.Save(file)
+	assert.NoErr(t, err)
+
+	for _, name := range []string{file, credentials} {
+		contents, err := ioutil.ReadFile(name)
+		assert.NoErr(t, err)
+		assert.Equal(t, strings.Contains(string(contents), `"a"`), false, fmt.Sprintf("token in %s", name))
+	}
+
+	s, err = Load(file)
+	assert.NoErr(t, err)
+	assert.Equal(t, s.Client.Token, "a", "token")
+
+	Passphrase = func() (string, error) { return "wrong", nil }
+	_, err = Load(file)
+	assert.Err(t, fmt.Errorf("could not decrypt %s, is the passphrase right?", credentials), err)
+
+	// Going back to the settings file erases the encrypted one.
+	Passphrase = func() (string, error) { return "correct horse", nil }
+	s.CredentialStore = FileCredentialStore
+	_, err = s.Save(file)
+	assert.NoErr(t, err)
+
+	if _, err = os.Stat(credentials); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be erased, got %v", credentials, err)
+	}
+
+	s, err = Load(file)
+	assert.NoErr(t, err)
+	assert.Equal(t, s.Client.Token, "a", "token")
+}
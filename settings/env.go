@@ -0,0 +1,73 @@
+package settings
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// Environment variables that override the settings file, so CI can run without one. Flags
+// given to a command, such as --limit, override them in turn.
+const (
+	ControllerEnv    = "DEIS_CONTROLLER"
+	TokenEnv         = "DEIS_TOKEN"
+	UsernameEnv      = "DEIS_USERNAME"
+	SSLVerifyEnv     = "DEIS_SSL_VERIFY"
+	ResponseLimitEnv = "DEIS_RESPONSE_LIMIT"
+)
+
+// DefaultSource is the source of a setting that wasn't set anywhere.
+const DefaultSource = "default"
+
+// SettingNames are the settings that Settings.Sources tells the source of, in the order to
+// show them.
+var SettingNames = []string{"controller", "username", "token", "ssl_verify", "response_limit"}
+
+// applyEnv overrides the settings read from a file with the environment, recording the
+// variable each one came from in sources.
+func applyEnv(sF *settingsFile, sources map[string]string) error {
+	if v := os.Getenv(ControllerEnv); v != "" {
+		// Credentials in the file belong to another controller, so they don't apply.
+		if v != sF.Controller {
+			sF.Username = ""
+			sF.Token = ""
+			sources["username"] = DefaultSource
+			sources["token"] = DefaultSource
+		}
+
+		sF.Controller = v
+		sources["controller"] = "$" + ControllerEnv
+	}
+
+	if v := os.Getenv(UsernameEnv); v != "" {
+		sF.Username = v
+		sources["username"] = "$" + UsernameEnv
+	}
+
+	if v := os.Getenv(TokenEnv); v != "" {
+		sF.Token = v
+		sources["token"] = "$" + TokenEnv
+	}
+
+	if v := os.Getenv(SSLVerifyEnv); v != "" {
+		verify, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("%s must be true or false, not %s", SSLVerifyEnv, v)
+		}
+
+		sF.VerifySSL = verify
+		sources["ssl_verify"] = "$" + SSLVerifyEnv
+	}
+
+	if v := os.Getenv(ResponseLimitEnv); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			return fmt.Errorf("%s must be a positive number, not %s", ResponseLimitEnv, v)
+		}
+
+		sF.Limit = limit
+		sources["response_limit"] = "$" + ResponseLimitEnv
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package settings
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arschles/assert"
+)
+
+// setEnv sets environment variables for a test, returning a func that restores them.
+func setEnv(vars map[string]string) func() {
+	previous := map[string]*string{}
+	for name, value := range vars {
+		if v, ok := os.LookupEnv(name); ok {
+			previous[name] = &v
+		} else {
+			previous[name] = nil
+		}
+		os.Setenv(name, value)
+	}
+
+	return func() {
+		for name, value := range previous {
+			if value == nil {
+				os.Unsetenv(name)
+			} else {
+				os.Setenv(name, *value)
+			}
+		}
+	}
+}
+
+func TestLoadFromEnv(t *testing.T) {
+	name, err := ioutil.TempDir("", "client")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(name)
+
+	// Without a settings file, the environment is enough.
+	restore := setEnv(map[string]string{ControllerEnv: "http://deis.ci", TokenEnv: "ci-token",
+		UsernameEnv: "ci"})
+	defer restore()
+
+	s, err := Load(filepath.Join(name, "missing.json"))
+	assert.NoErr(t, err)
+	assert.Equal(t, s.Client.ControllerURL.String(), "http://deis.ci", "controller")
+	assert.Equal(t, s.Client.Token, "ci-token", "token")
+	assert.Equal(t, s.Username, "ci", "username")
+	assert.Equal(t, s.Client.VerifySSL, true, "ssl verify")
+	assert.Equal(t, s.Limit, DefaultResponseLimit, "limit")
+	assert.Equal(t, s.Sources, map[string]string{
+		"controller":     "$DEIS_CONTROLLER",
+		"username":       "$DEIS_USERNAME",
+		"token":          "$DEIS_TOKEN",
+		"ssl_verify":     DefaultSource,
+		"response_limit": DefaultSource,
+	}, "sources")
+
+	// The environment overrides the settings file.
+	file, err := createTempProfile(`{"username":"t","ssl_verify":true,"controller":"http://deis.ci","token":"a","response_limit":20}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restore = setEnv(map[string]string{UsernameEnv: "", SSLVerifyEnv: "false",
+		ResponseLimitEnv: "5"})
+	defer restore()
+
+	s, err = Load(file)
+	assert.NoErr(t, err)
+	assert.Equal(t, s.Client.Token, "ci-token", "token")
+	assert.Equal(t, s.Username, "t", "username")
+	assert.Equal(t, s.Client.VerifySSL, false, "ssl verify")
+	assert.Equal(t, s.Limit, 5, "limit")
+	assert.Equal(t, s.Sources, map[string]string{
+		"controller":     "$DEIS_CONTROLLER",
+		"username":       file,
+		"token":          "$DEIS_TOKEN",
+		"ssl_verify":     "$DEIS_SSL_VERIFY",
+		"response_limit": "$DEIS_RESPONSE_LIMIT",
+	}, "sources")
+
+	// The credentials in the file don't apply to another controller.
+	restore = setEnv(map[string]string{ControllerEnv: "http://deis.other", TokenEnv: ""})
+	defer restore()
+
+	s, err = Load(file)
+	assert.NoErr(t, err)
+	assert.Equal(t, s.Client.Token, "", "token")
+	assert.Equal(t, s.Username, "", "username")
+	assert.Equal(t, s.Sources["token"], DefaultSource, "token source")
+
+	restore = setEnv(map[string]string{SSLVerifyEnv: "maybe"})
+	defer restore()
+
+	_, err = Load(file)
+	assert.Err(t, errors.New("DEIS_SSL_VERIFY must be true or false, not maybe"), err)
+}
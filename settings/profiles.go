@@ -0,0 +1,243 @@
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// currentProfileFile is the file in ~/.deis that records the profile used when neither
+// --config nor $DEIS_PROFILE is given.
+const currentProfileFile = "current_profile"
+
+// defaultProfile is the profile used when no current profile has been selected.
+const defaultProfile = "client"
+
+var profileNameRegex = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// Profile describes a settings file stored in ~/.deis.
+type Profile struct {
+	Name       string `json:"name"`
+	Path       string `json:"path"`
+	Username   string `json:"username"`
+	Controller string `json:"controller"`
+	VerifySSL  bool   `json:"ssl_verify"`
+	Active     bool   `json:"active"`
+}
+
+func profilesDir() string {
+	return filepath.Join(FindHome(), ".deis")
+}
+
+func validateProfileName(name string) error {
+	if !profileNameRegex.MatchString(name) || strings.HasSuffix(name, ".json") {
+		return fmt.Errorf("%s is not a valid profile name, use letters, digits, '.', '_' or '-'", name)
+	}
+
+	return nil
+}
+
+// CurrentProfile returns the name of the profile selected with 'deis profiles:use'.
+func CurrentProfile() string {
+	return currentProfile(profilesDir())
+}
+
+func currentProfile(dir string) string {
+	contents, err := ioutil.ReadFile(filepath.Join(dir, currentProfileFile))
+	if err != nil {
+		return defaultProfile
+	}
+
+	name := strings.TrimSpace(string(contents))
+	if validateProfileName(name) != nil {
+		return defaultProfile
+	}
+
+	return name
+}
+
+// ListProfiles returns all profiles stored in ~/.deis. The profile that cf resolves to is
+// marked as active.
+func ListProfiles(cf string) ([]Profile, error) {
+	return listProfiles(profilesDir(), locateSettingsFile(cf))
+}
+
+func listProfiles(dir, active string) ([]Profile, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(files)
+
+	profiles := []Profile{}
+	for _, file := range files {
+		profile, err := readProfile(file)
+		if err != nil {
+			// Skip json files in ~/.deis that aren't client configuration.
+			continue
+		}
+
+		profile.Active = file == active
+		profiles = append(profiles, profile)
+	}
+
+	return profiles, nil
+}
+
+// GetProfile returns the profile with the given name. If name is empty, the profile that cf
+// resolves to is returned.
+func GetProfile(cf, name string) (Profile, error) {
+	active := locateSettingsFile(cf)
+
+	if name == "" {
+		profile, err := readProfile(active)
+		if err == nil {
+			profile.Active = true
+		}
+		return profile, err
+	}
+
+	if err := validateProfileName(name); err != nil {
+		return Profile{}, err
+	}
+
+	profile, err := readProfile(filepath.Join(profilesDir(), name+".json"))
+	if err == nil {
+		profile.Active = profile.Path == active
+	}
+	return profile, err
+}
+
+func readProfile(filename string) (Profile, error) {
+	contents, err := ioutil.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Profile{}, fmt.Errorf("profile %s not found", profileName(filename))
+		}
+		return Profile{}, err
+	}
+
+	sF := settingsFile{}
+	if err = json.Unmarshal(contents, &sF); err != nil {
+		return Profile{}, err
+	}
+
+	if sF.Controller == "" {
+		return Profile{}, fmt.Errorf("%s is not a client configuration file", filename)
+	}
+
+	return Profile{
+		Name:       profileName(filename),
+		Path:       filename,
+		Username:   sF.Username,
+		Controller: sF.Controller,
+		VerifySSL:  sF.VerifySSL,
+	}, nil
+}
+
+func profileName(filename string) string {
+	return strings.TrimSuffix(filepath.Base(filename), ".json")
+}
+
+// UseProfile makes name the profile used when neither --config nor $DEIS_PROFILE is given.
+func UseProfile(name string) error {
+	return useProfile(profilesDir(), name)
+}
+
+func useProfile(dir, name string) error {
+	if err := validateProfileName(name); err != nil {
+		return err
+	}
+
+	if _, err := readProfile(filepath.Join(dir, name+".json")); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath.Join(dir, currentProfileFile), []byte(name+"\n"), 0600)
+}
+
+// RenameProfile renames a profile, keeping it current if it was.
+func RenameProfile(oldName, newName string) error {
+	return renameProfile(profilesDir(), oldName, newName)
+}
+
+func renameProfile(dir, oldName, newName string) error {
+	for _, name := range []string{oldName, newName} {
+		if err := validateProfileName(name); err != nil {
+			return err
+		}
+	}
+
+	oldPath := filepath.Join(dir, oldName+".json")
+	newPath := filepath.Join(dir, newName+".json")
+
+	if _, err := readProfile(oldPath); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(newPath); err == nil {
+		return fmt.Errorf("profile %s already exists", newName)
+	}
+
+	if err := os.Rename(oldPath, newPath); err != nil {
+		return err
+	}
+
+	// The encrypted token isn't tied to the file name, so it moves along with the profile.
+	err := os.Rename(credentialsFile(oldPath), credentialsFile(newPath))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if currentProfile(dir) == oldName {
+		return ioutil.WriteFile(filepath.Join(dir, currentProfileFile), []byte(newName+"\n"), 0600)
+	}
+
+	return nil
+}
+
+// DeleteProfile removes a profile. If it was the current profile, the default profile is
+// used from then on.
+func DeleteProfile(name string) error {
+	return deleteProfile(profilesDir(), name)
+}
+
+func deleteProfile(dir, name string) error {
+	if err := validateProfileName(name); err != nil {
+		return err
+	}
+
+	filename := filepath.Join(dir, name+".json")
+
+	if _, err := readProfile(filename); err != nil {
+		return err
+	}
+
+	sF, err := readSettingsFile(filename)
+	if err != nil {
+		return err
+	}
+
+	// Don't leave the token behind in a credential store.
+	if err = eraseCredentials(sF, filename); err != nil {
+		return err
+	}
+
+	if err = os.Remove(filename); err != nil {
+		return err
+	}
+
+	if currentProfile(dir) == name {
+		if err := os.Remove(filepath.Join(dir, currentProfileFile)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
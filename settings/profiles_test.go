@@ -0,0 +1,128 @@
+package settings
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arschles/assert"
+)
+
+func createProfilesDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "profiles")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	profiles := map[string]string{
+		"client.json":  `{"username":"admin","ssl_verify":true,"controller":"http://deis.staging.example.com","token":"a"}`,
+		"prod-us.json": `{"username":"ops","ssl_verify":false,"controller":"http://deis.prod-us.example.com","token":"b"}`,
+		"other.json":   `{"unrelated":true}`,
+	}
+
+	for name, contents := range profiles {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+// writeCredentials moves the token of a profile to the encrypted-file store.
+func writeCredentials(t *testing.T, dir, name string) {
+	profile := `{"username":"ops","controller":"http://deis.prod-us.example.com","credential_store":"encrypted-file"}`
+	if err := ioutil.WriteFile(filepath.Join(dir, name+".json"), []byte(profile), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, name+".credentials"), []byte(`{}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestListProfiles(t *testing.T) {
+	t.Parallel()
+	dir := createProfilesDir(t)
+	defer os.RemoveAll(dir)
+
+	profiles, err := listProfiles(dir, filepath.Join(dir, "prod-us.json"))
+	assert.NoErr(t, err)
+	assert.Equal(t, profiles, []Profile{
+		{
+			Name:       "client",
+			Path:       filepath.Join(dir, "client.json"),
+			Username:   "admin",
+			Controller: "http://deis.staging.example.com",
+			VerifySSL:  true,
+		},
+		{
+			Name:       "prod-us",
+			Path:       filepath.Join(dir, "prod-us.json"),
+			Username:   "ops",
+			Controller: "http://deis.prod-us.example.com",
+			Active:     true,
+		},
+	}, "profiles")
+}
+
+func TestUseProfile(t *testing.T) {
+	t.Parallel()
+	dir := createProfilesDir(t)
+	defer os.RemoveAll(dir)
+
+	assert.Equal(t, currentProfile(dir), "client", "current profile")
+
+	assert.NoErr(t, useProfile(dir, "prod-us"))
+	assert.Equal(t, currentProfile(dir), "prod-us", "current profile")
+
+	assert.ExistsErr(t, useProfile(dir, "missing"), "missing profile")
+	assert.ExistsErr(t, useProfile(dir, "../prod-us"), "invalid name")
+	assert.ExistsErr(t, useProfile(dir, "other"), "not a profile")
+	assert.Equal(t, currentProfile(dir), "prod-us", "current profile")
+}
+
+func TestRenameProfile(t *testing.T) {
+	t.Parallel()
+	dir := createProfilesDir(t)
+	defer os.RemoveAll(dir)
+
+	writeCredentials(t, dir, "prod-us")
+
+	assert.NoErr(t, useProfile(dir, "prod-us"))
+	assert.NoErr(t, renameProfile(dir, "prod-us", "prod-eu"))
+	assert.Equal(t, currentProfile(dir), "prod-eu", "current profile")
+
+	for _, name := range []string{"prod-eu.json", "prod-eu.credentials"} {
+		_, err := os.Stat(filepath.Join(dir, name))
+		assert.NoErr(t, err)
+	}
+
+	_, err := os.Stat(filepath.Join(dir, "prod-us.credentials"))
+	assert.Equal(t, os.IsNotExist(err), true, "credentials moved")
+
+	err = renameProfile(dir, "prod-eu", "client")
+	assert.Err(t, errors.New("profile client already exists"), err)
+	assert.ExistsErr(t, renameProfile(dir, "missing", "new"), "missing profile")
+}
+
+func TestDeleteProfile(t *testing.T) {
+	t.Parallel()
+	dir := createProfilesDir(t)
+	defer os.RemoveAll(dir)
+
+	writeCredentials(t, dir, "prod-us")
+
+	assert.NoErr(t, useProfile(dir, "prod-us"))
+	assert.NoErr(t, deleteProfile(dir, "prod-us"))
+	assert.Equal(t, currentProfile(dir), "client", "current profile")
+
+	for _, name := range []string{"prod-us.json", "prod-us.credentials"} {
+		_, err := os.Stat(filepath.Join(dir, name))
+		assert.Equal(t, os.IsNotExist(err), true, name+" removed")
+	}
+
+	assert.ExistsErr(t, deleteProfile(dir, "prod-us"), "missing profile")
+}
@@ -0,0 +1,34 @@
+package settings
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ReadOnly makes the clients created by Load refuse requests that would change anything on
+// the controller. It is set by --dry-run, so commands that can't preview their changes fail
+// instead of applying them.
+var ReadOnly = false
+
+// ErrReadOnly is returned for the requests refused because of ReadOnly.
+var ErrReadOnly = errors.New("this command doesn't support --dry-run, nothing was changed")
+
+type readOnlyTransport struct {
+	next http.RoundTripper
+}
+
+func (t readOnlyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return nil, ErrReadOnly
+	}
+
+	return t.next.RoundTrip(req)
+}
+
+func newReadOnlyTransport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return readOnlyTransport{next: next}
+}
@@ -0,0 +1,38 @@
+package settings
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/arschles/assert"
+)
+
+func TestReadOnlyTransport(t *testing.T) {
+	t.Parallel()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	client := http.Client{Transport: newReadOnlyTransport(nil)}
+
+	res, err := client.Get(server.URL)
+	assert.NoErr(t, err)
+	res.Body.Close()
+
+	for _, method := range []string{"POST", "PUT", "PATCH", "DELETE"} {
+		req, err := http.NewRequest(method, server.URL, strings.NewReader("{}"))
+		assert.NoErr(t, err)
+
+		_, err = client.Do(req)
+		if err == nil || !strings.Contains(err.Error(), ErrReadOnly.Error()) {
+			t.Errorf("%s: expected %v, got %v", method, ErrReadOnly, err)
+		}
+	}
+
+	assert.Equal(t, requests, 1, "requests")
+}
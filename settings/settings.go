@@ -18,45 +18,108 @@ const DefaultResponseLimit = 100
 // UserAgent is the user agent used by the CLI
 var UserAgent = "Deis Client " + version.Version
 
+// The ways of logging in, recorded so that an expired session can be renewed the same way.
+const (
+	PasswordLogin = "password"
+	GoogleLogin   = "google"
+	SSOLogin      = "sso"
+)
+
 type settingsFile struct {
 	Username   string `json:"username"`
 	VerifySSL  bool   `json:"ssl_verify"`
 	Controller string `json:"controller"`
-	Token      string `json:"token"`
+	Token      string `json:"token,omitempty"`
 	Limit      int    `json:"response_limit"`
+	// SecretPatterns are extra config key patterns, such as "*_API_KEY", to mask as secrets.
+	SecretPatterns []string `json:"secret_patterns,omitempty"`
+	// CredentialStore is where the token is kept, the settings file if it's empty.
+	CredentialStore string `json:"credential_store,omitempty"`
+	// CAFile, ClientCert and ClientKey are PEM files to verify the controller's certificate
+	// with, and to authenticate to it with mutual TLS.
+	CAFile     string `json:"ca_file,omitempty"`
+	ClientCert string `json:"client_cert,omitempty"`
+	ClientKey  string `json:"client_key,omitempty"`
+	// LoginMethod is how the user logged in, a password login if it's empty. SSOIssuer and
+	// SSOClientID are the provider given to an SSO login instead of the controller's.
+	LoginMethod string `json:"login_method,omitempty"`
+	SSOIssuer   string `json:"sso_issuer,omitempty"`
+	SSOClientID string `json:"sso_client_id,omitempty"`
 }
 
 // Settings is the settings object created from the settings file.
 type Settings struct {
-	Username string
-	Limit    int
-	Client   *deis.Client
+	Username       string
+	Limit          int
+	SecretPatterns []string
+	// CredentialStore is file, encrypted-file or the name of a credential helper.
+	CredentialStore string
+	// CAFile, ClientCert and ClientKey are the certificate files the client uses, if any.
+	CAFile     string
+	ClientCert string
+	ClientKey  string
+	// LoginMethod is PasswordLogin, GoogleLogin or SSOLogin, or empty for settings saved before
+	// it was recorded. SSOIssuer and SSOClientID are only set if they were given to an SSO login.
+	LoginMethod string
+	SSOIssuer   string
+	SSOClientID string
+	// Sources tells where each of SettingNames came from: the settings file, an environment
+	// variable, a credential store or DefaultSource.
+	Sources map[string]string
+	Client  *deis.Client
 }
 
-// Load loads a new client from a settings file.
+// Load loads a new client from a settings file. $DEIS_CONTROLLER, $DEIS_TOKEN, $DEIS_USERNAME,
+// $DEIS_SSL_VERIFY and $DEIS_RESPONSE_LIMIT override the settings in the file, and with
+// $DEIS_CONTROLLER set, the file doesn't need to exist.
 func Load(cf string) (*Settings, error) {
 	filename := locateSettingsFile(cf)
 
-	if _, err := os.Stat(filename); err != nil {
-		if os.IsNotExist(err) {
-			return nil, fmt.Errorf(`Client configuration file not found at: %s
-Are you logged in? Use 'deis login' or 'deis register' to get started.`, filename)
+	sF := &settingsFile{}
+	sources := map[string]string{}
+
+	if _, err := os.Stat(filename); err == nil {
+		if sF, err = readSettingsFile(filename); err != nil {
+			return nil, err
 		}
 
+		for _, name := range SettingNames {
+			sources[name] = filename
+		}
+	} else if os.IsNotExist(err) && os.Getenv(ControllerEnv) != "" {
+		// Without a file, verify certificates unless told otherwise, as login does.
+		sF.VerifySSL = true
+		for _, name := range SettingNames {
+			sources[name] = DefaultSource
+		}
+	} else if os.IsNotExist(err) {
+		return nil, fmt.Errorf(`Client configuration file not found at: %s
+Are you logged in? Use 'deis login' or 'deis register' to get started.`, filename)
+	} else {
 		return nil, err
 	}
 
-	contents, err := ioutil.ReadFile(filename)
-	if err != nil {
+	if err := applyEnv(sF, sources); err != nil {
 		return nil, err
 	}
 
-	sF := settingsFile{}
-	if err = json.Unmarshal(contents, &sF); err != nil {
-		return nil, err
+	token := sF.Token
+	if sources["token"] == filename {
+		store := credentialStore(sF, filename)
+
+		// A missing token isn't an error here, the controller answers that the user isn't logged in.
+		var err error
+		token, err = store.Get(sF.Controller, sF.Username)
+		if err == ErrCredentialsNotFound {
+			sources["token"] = DefaultSource
+		} else if err != nil {
+			return nil, err
+		} else if _, ok := store.(*fileStore); !ok {
+			sources["token"] = fmt.Sprintf("%s credential store", sF.CredentialStore)
+		}
 	}
 
-	c, err := deis.New(sF.VerifySSL, sF.Controller, sF.Token)
+	c, err := deis.New(sF.VerifySSL, sF.Controller, token)
 
 	if err != nil {
 		return nil, err
@@ -65,8 +128,25 @@ Are you logged in? Use 'deis login' or 'deis register' to get started.`, filenam
 	// Set a custom user agent
 	c.UserAgent = UserAgent
 
+	if err = ConfigureTLS(c, sF.CAFile, sF.ClientCert, sF.ClientKey); err != nil {
+		return nil, err
+	}
+
+	if ReadOnly {
+		c.HTTPClient.Transport = newReadOnlyTransport(c.HTTPClient.Transport)
+	}
+
 	settings := Settings{}
 	settings.Username = sF.Username
+	settings.SecretPatterns = sF.SecretPatterns
+	settings.CredentialStore = sF.CredentialStore
+	settings.CAFile = sF.CAFile
+	settings.ClientCert = sF.ClientCert
+	settings.ClientKey = sF.ClientKey
+	settings.LoginMethod = sF.LoginMethod
+	settings.SSOIssuer = sF.SSOIssuer
+	settings.SSOClientID = sF.SSOClientID
+	settings.Sources = sources
 	settings.Client = c
 
 	// If users have defined a custom response limit, respect it.
@@ -74,32 +154,70 @@ Are you logged in? Use 'deis login' or 'deis register' to get started.`, filenam
 		settings.Limit = sF.Limit
 	} else {
 		settings.Limit = DefaultResponseLimit
+		sources["response_limit"] = DefaultSource
 	}
 
 	return &settings, nil
 }
 
-// Save settings to a file
+// Saved returns the settings written in a settings file, without the environment overrides and
+// without getting the token from the credential store, so that logging in again can keep them
+// even when the store can't be read. The error satisfies os.IsNotExist if there's no file.
+func Saved(cf string) (*Settings, error) {
+	sF, err := readSettingsFile(locateSettingsFile(cf))
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := deis.New(sF.VerifySSL, sF.Controller, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Settings{Username: sF.Username, Limit: sF.Limit, SecretPatterns: sF.SecretPatterns,
+		CredentialStore: sF.CredentialStore, CAFile: sF.CAFile, ClientCert: sF.ClientCert,
+		ClientKey: sF.ClientKey, LoginMethod: sF.LoginMethod, SSOIssuer: sF.SSOIssuer,
+		SSOClientID: sF.SSOClientID, Client: c}, nil
+}
+
+// Save settings to a file, and the token to the credential store.
 func (s *Settings) Save(cf string) (string, error) {
 	settings := settingsFile{Username: s.Username, VerifySSL: s.Client.VerifySSL,
-		Controller: s.Client.ControllerURL.String(), Token: s.Client.Token, Limit: s.Limit}
+		Controller: s.Client.ControllerURL.String(), Limit: s.Limit,
+		SecretPatterns: s.SecretPatterns, CredentialStore: s.CredentialStore, CAFile: s.CAFile,
+		ClientCert: s.ClientCert, ClientKey: s.ClientKey, LoginMethod: s.LoginMethod,
+		SSOIssuer: s.SSOIssuer, SSOClientID: s.SSOClientID}
 
-	settingsContents, err := json.Marshal(settings)
+	if err := os.MkdirAll(filepath.Join(FindHome(), "/.deis/"), 0700); err != nil {
+		return "", err
+	}
+
+	filename := locateSettingsFile(cf)
+
+	// Don't leave the token behind in the store the settings used before.
+	if previous, err := readSettingsFile(filename); err == nil &&
+		previous.CredentialStore != settings.CredentialStore {
+		if err = eraseCredentials(previous, filename); err != nil {
+			return "", err
+		}
+	}
 
+	err := credentialStore(&settings, filename).Store(settings.Controller, settings.Username,
+		s.Client.Token)
 	if err != nil {
 		return "", err
 	}
 
-	if err = os.MkdirAll(filepath.Join(FindHome(), "/.deis/"), 0700); err != nil {
+	settingsContents, err := json.Marshal(settings)
+
+	if err != nil {
 		return "", err
 	}
 
-	filename := locateSettingsFile(cf)
-
 	return filename, ioutil.WriteFile(filename, settingsContents, 0600)
 }
 
-// Delete user's settings file.
+// Delete user's settings file, and the token in the credential store.
 func Delete(cf string) error {
 	filename := locateSettingsFile(cf)
 
@@ -111,9 +229,39 @@ func Delete(cf string) error {
 		return err
 	}
 
+	// A settings file that can't be read has no token to erase, but can still be removed.
+	if sF, err := readSettingsFile(filename); err == nil {
+		if err = eraseCredentials(sF, filename); err != nil {
+			return err
+		}
+	}
+
 	if err := os.Remove(filename); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+func readSettingsFile(filename string) (*settingsFile, error) {
+	contents, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	sF := settingsFile{}
+	if err = json.Unmarshal(contents, &sF); err != nil {
+		return nil, err
+	}
+
+	return &sF, nil
+}
+
+func eraseCredentials(sF *settingsFile, filename string) error {
+	err := credentialStore(sF, filename).Erase(sF.Controller, sF.Username)
+	if err == ErrCredentialsNotFound {
+		return nil
+	}
+
+	return err
+}
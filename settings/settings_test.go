@@ -141,6 +141,27 @@ func TestLoadSave(t *testing.T) {
 	}
 }
 
+func TestSecretPatterns(t *testing.T) {
+	t.Parallel()
+
+	file, err := createTempProfile(`{"username":"t","controller":"http://foo.bar","token":"a","secret_patterns":["*_KEY"]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Load(file)
+	assert.NoErr(t, err)
+	assert.Equal(t, s.SecretPatterns, []string{"*_KEY"}, "secret patterns")
+
+	s.SecretPatterns = append(s.SecretPatterns, "*_CREDENTIALS")
+	_, err = s.Save(file)
+	assert.NoErr(t, err)
+
+	s, err = Load(file)
+	assert.NoErr(t, err)
+	assert.Equal(t, s.SecretPatterns, []string{"*_KEY", "*_CREDENTIALS"}, "secret patterns")
+}
+
 func checkComparisons(tests []comparison) error {
 	for _, check := range tests {
 		if check.key != check.expected {
@@ -188,3 +209,25 @@ func TestNotLoggedIn(t *testing.T) {
 		t.Error("Expected configuration error, Got:", err.Error())
 	}
 }
+
+func TestSaved(t *testing.T) {
+	t.Parallel()
+
+	// The settings are read without the credential helper, which isn't installed.
+	file, err := createTempProfile(`{"username":"t","controller":"http://foo.bar","credential_store":"missing","ca_file":"/ca.pem","login_method":"sso"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Saved(file)
+	assert.NoErr(t, err)
+	assert.Equal(t, s.CredentialStore, "missing", "credential store")
+	assert.Equal(t, s.CAFile, "/ca.pem", "CA file")
+	assert.Equal(t, s.LoginMethod, SSOLogin, "login method")
+	assert.Equal(t, s.Client.Token, "", "token")
+
+	_, err = Saved(filepath.Join(filepath.Dir(file), "other.json"))
+	if !os.IsNotExist(err) {
+		t.Errorf("expected the file not to exist, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package settings
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	deis "github.com/deis/controller-sdk-go"
+)
+
+// ConfigureTLS makes a client trust the CA certificates in caFile along with the system's,
+// and authenticate to the controller with the certificate in clientCert and its key in
+// clientKey. Empty files are left out, so controllers with a private CA can be verified
+// instead of turning verification off.
+func ConfigureTLS(c *deis.Client, caFile, clientCert, clientKey string) error {
+	if caFile == "" && clientCert == "" && clientKey == "" {
+		return nil
+	}
+
+	transport, ok := c.HTTPClient.Transport.(*http.Transport)
+	if !ok {
+		return errors.New("the HTTP client doesn't support custom certificates")
+	}
+
+	config := &tls.Config{}
+	if transport.TLSClientConfig != nil {
+		config = transport.TLSClientConfig.Clone()
+	}
+
+	if caFile != "" {
+		contents, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return err
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			// Windows has no system pool to add to before Go 1.18.
+			pool = x509.NewCertPool()
+		}
+
+		if !pool.AppendCertsFromPEM(contents) {
+			return fmt.Errorf("no PEM certificates found in %s", caFile)
+		}
+
+		config.RootCAs = pool
+	}
+
+	if clientCert != "" || clientKey != "" {
+		if clientCert == "" || clientKey == "" {
+			return errors.New("a client certificate needs both client_cert and client_key")
+		}
+
+		cert, err := tls.LoadX509KeyPair(clientCert, clientKey)
+		if err != nil {
+			return fmt.Errorf("could not load the client certificate: %v", err)
+		}
+
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	transport.TLSClientConfig = config
+	return nil
+}
@@ -0,0 +1,134 @@
+package settings
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/arschles/assert"
+)
+
+// writeClientCert writes a self-signed client certificate and its key to dir.
+func writeClientCert(t *testing.T, dir string) (*x509.Certificate, string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "deis-cli"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client-key.pem")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+
+	return cert, certFile, keyFile
+}
+
+func writePEM(t *testing.T, filename, blockType string, der []byte) {
+	if err := ioutil.WriteFile(filename, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadTLS(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	clientCert, certFile, keyFile := writeClientCert(t, dir)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.TLS.PeerCertificates[0].Subject.CommonName)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	// Don't log the handshakes that fail on purpose.
+	server.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	caFile := filepath.Join(dir, "ca.pem")
+	writePEM(t, caFile, "CERTIFICATE", server.Certificate().Raw)
+
+	profile := func(files string) string {
+		file, err := createTempProfile(fmt.Sprintf(`{"username":"t","ssl_verify":true,"controller":"%s","token":"a"%s}`,
+			server.URL, files))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return file
+	}
+
+	// Without the CA, the controller's certificate can't be verified.
+	s, err := Load(profile(""))
+	assert.NoErr(t, err)
+	_, err = s.Client.HTTPClient.Get(server.URL)
+	assert.ExistsErr(t, err, "unverified certificate")
+
+	// Without a client certificate, the controller refuses the connection.
+	s, err = Load(profile(fmt.Sprintf(`,"ca_file":"%s"`, caFile)))
+	assert.NoErr(t, err)
+	_, err = s.Client.HTTPClient.Get(server.URL)
+	assert.ExistsErr(t, err, "missing client certificate")
+
+	s, err = Load(profile(fmt.Sprintf(`,"ca_file":"%s","client_cert":"%s","client_key":"%s"`, caFile, certFile,
+		keyFile)))
+	assert.NoErr(t, err)
+	assert.Equal(t, s.CAFile, caFile, "CA file")
+	res, err := s.Client.HTTPClient.Get(server.URL)
+	assert.NoErr(t, err)
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	assert.NoErr(t, err)
+	assert.Equal(t, string(body), "deis-cli", "client certificate")
+
+	_, err = Load(profile(fmt.Sprintf(`,"client_cert":"%s"`, certFile)))
+	assert.Err(t, errors.New("a client certificate needs both client_cert and client_key"), err)
+
+	_, err = Load(profile(fmt.Sprintf(`,"ca_file":"%s"`, keyFile)))
+	assert.Err(t, fmt.Errorf("no PEM certificates found in %s", keyFile), err)
+}
@@ -13,7 +13,7 @@ func locateSettingsFile(cf string) string {
 		if v, ok := os.LookupEnv("DEIS_PROFILE"); ok {
 			cf = v
 		} else {
-			cf = "client"
+			cf = CurrentProfile()
 		}
 	}
 